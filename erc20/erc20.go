@@ -0,0 +1,181 @@
+// Package erc20 provides functionality associated with ERC20 tokens.
+package erc20
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+// A Token describes the denomination of an ERC20 token, as returned by its
+// symbol() and decimals() functions.
+type Token struct {
+	Symbol   string
+	Decimals uint8
+}
+
+// Common tokens. ETH isn't an ERC20 but is included for convenience as it
+// shares the same denomination as wETH.
+var (
+	ETH  = Token{Symbol: "ETH", Decimals: eth.EtherDecimals}
+	WETH = Token{Symbol: "WETH", Decimals: eth.EtherDecimals}
+)
+
+// Amount returns an Amount of t, with value x in the token's base units (i.e.
+// the equivalent of Wei).
+func (t Token) Amount(x *big.Int) Amount {
+	return Amount{Token: t, Value: new(big.Int).Set(x)}
+}
+
+// Units returns n whole units of t; e.g. WETH.Units(2) is 2e18 base units.
+func (t Token) Units(n int64) Amount {
+	x := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(t.Decimals)), nil)
+	return Amount{Token: t, Value: x.Mul(x, big.NewInt(n))}
+}
+
+// Parse parses the decimal string s, in whole units of t, optionally followed
+// by whitespace and t.Symbol (case sensitive); e.g. "0.0825" or "0.0825 WETH".
+// See eth.ParseUnits() for the accepted format; most notably, an error is
+// returned if precision would be lost.
+func (t Token) Parse(s string) (Amount, error) {
+	fields := strings.Fields(s)
+	switch {
+	case len(fields) == 2 && fields[1] == t.Symbol:
+	case len(fields) == 1:
+	default:
+		return Amount{}, fmt.Errorf("parse %q as %s amount: want <decimal> [%s]", s, t.Symbol, t.Symbol)
+	}
+
+	x, err := eth.ParseUnits(fields[0], t.Decimals)
+	if err != nil {
+		return Amount{}, err
+	}
+	return Amount{Token: t, Value: x}, nil
+}
+
+// An Amount is a quantity of a specific Token. Its Value is in the token's base
+// units; e.g. 1 WETH has a Value of 1e18.
+//
+// Methods never modify the receiver nor the arguments, and Amounts MUST NOT be
+// modified after construction as their Values may be shared. Arithmetic
+// between Amounts of different Tokens returns an error as this is almost
+// certainly a bug.
+type Amount struct {
+	Token Token
+	Value *big.Int
+}
+
+// value returns a.Value, or zero if it is nil, allowing the zero Amount to be
+// used.
+func (a Amount) value() *big.Int {
+	if a.Value == nil {
+		return new(big.Int)
+	}
+	return a.Value
+}
+
+// checkToken returns an error if a and o are of different Tokens.
+func (a Amount) checkToken(o Amount) error {
+	if a.Token != o.Token {
+		return fmt.Errorf("mismatched tokens %+v and %+v", a.Token, o.Token)
+	}
+	return nil
+}
+
+// Add returns a+o.
+func (a Amount) Add(o Amount) (Amount, error) {
+	if err := a.checkToken(o); err != nil {
+		return Amount{}, err
+	}
+	return Amount{Token: a.Token, Value: new(big.Int).Add(a.value(), o.value())}, nil
+}
+
+// Sub returns a-o.
+func (a Amount) Sub(o Amount) (Amount, error) {
+	if err := a.checkToken(o); err != nil {
+		return Amount{}, err
+	}
+	return Amount{Token: a.Token, Value: new(big.Int).Sub(a.value(), o.value())}, nil
+}
+
+// Mul returns a*n; e.g. the total cost of n items priced at a.
+func (a Amount) Mul(n int64) Amount {
+	return Amount{Token: a.Token, Value: new(big.Int).Mul(a.value(), big.NewInt(n))}
+}
+
+// Cmp compares a and o and returns:
+//
+//	-1 if a <  o
+//	 0 if a == o
+//	 1 if a >  o
+//
+// An error is returned if a and o are of different Tokens.
+func (a Amount) Cmp(o Amount) (int, error) {
+	if err := a.checkToken(o); err != nil {
+		return 0, err
+	}
+	return a.value().Cmp(o.value()), nil
+}
+
+// Equal reports whether a and o are of the same Token and have equal Values. A
+// nil Value is treated as zero.
+func (a Amount) Equal(o Amount) bool {
+	return a.Token == o.Token && a.value().Cmp(o.value()) == 0
+}
+
+// Sign returns -1, 0, or 1 depending on the sign of a.Value.
+func (a Amount) Sign() int {
+	return a.value().Sign()
+}
+
+// String returns the exact decimal value of a in whole units, followed by the
+// Token symbol if it is non-empty; e.g. "0.0825 WETH".
+func (a Amount) String() string {
+	return a.withSymbol(eth.FormatUnits(a.value(), a.Token.Decimals))
+}
+
+// Format returns a in whole units, with exactly precision fractional digits,
+// followed by the Token symbol if it is non-empty. See eth.FormatUnitsFixed()
+// re rounding.
+func (a Amount) Format(precision uint8) string {
+	return a.withSymbol(eth.FormatUnitsFixed(a.value(), a.Token.Decimals, precision))
+}
+
+func (a Amount) withSymbol(s string) string {
+	if a.Token.Symbol == "" {
+		return s
+	}
+	return s + " " + a.Token.Symbol
+}
+
+// MarshalJSON marshals a.Value, in base units, as a decimal JSON string. Token
+// information is not included and a string is used as JSON numbers are
+// typically parsed as float64 values, which can't represent most Amounts.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.value().String())
+}
+
+// UnmarshalJSON parses a decimal string of base units, as produced by
+// MarshalJSON(), into a.Value. The JSON value MAY also be a number, provided
+// that it is an integer. The Token is not modified, allowing it to be set
+// before unmarshalling.
+func (a *Amount) UnmarshalJSON(buf []byte) error {
+	var s string
+	if err := json.Unmarshal(buf, &s); err != nil {
+		var n json.Number
+		if err := json.Unmarshal(buf, &n); err != nil {
+			return fmt.Errorf("unmarshal %T: want string or number; got %s", a, buf)
+		}
+		s = n.String()
+	}
+
+	x, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return fmt.Errorf("unmarshal %T: invalid integer %q", a, s)
+	}
+	a.Value = x
+	return nil
+}
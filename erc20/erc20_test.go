@@ -0,0 +1,194 @@
+package erc20
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/h-fam/errdiff"
+)
+
+var usdc = Token{Symbol: "USDC", Decimals: 6}
+
+func TestParseAndFormat(t *testing.T) {
+	tests := []struct {
+		token          Token
+		in             string
+		want           *big.Int
+		wantString     string
+		errDiffAgainst interface{}
+	}{
+		{
+			token:      WETH,
+			in:         "0.0825",
+			want:       eth.EtherFraction(825, 10000),
+			wantString: "0.0825 WETH",
+		},
+		{
+			token:      WETH,
+			in:         "0.0825 WETH",
+			want:       eth.EtherFraction(825, 10000),
+			wantString: "0.0825 WETH",
+		},
+		{
+			token:      usdc,
+			in:         "12.50",
+			want:       big.NewInt(12500000),
+			wantString: "12.5 USDC",
+		},
+		{
+			token:      Token{Decimals: 2},
+			in:         "1",
+			want:       big.NewInt(100),
+			wantString: "1",
+		},
+		{
+			token:          usdc,
+			in:             "0.0000001",
+			errDiffAgainst: "precision would be lost",
+		},
+		{
+			token:          usdc,
+			in:             "1 WETH",
+			errDiffAgainst: "want <decimal> [USDC]",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.token.Parse(tt.in)
+		if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+			t.Errorf("%+v.Parse(%q) %s", tt.token, tt.in, diff)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got.Value.Cmp(tt.want) != 0 || got.Token != tt.token {
+			t.Errorf("%+v.Parse(%q) got %+v; want value %d", tt.token, tt.in, got, tt.want)
+		}
+		if s := got.String(); s != tt.wantString {
+			t.Errorf("%+v.Parse(%q).String() got %q; want %q", tt.token, tt.in, s, tt.wantString)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	a := WETH.Amount(eth.EtherFraction(825, 10000))
+	for precision, want := range map[uint8]string{
+		0: "0 WETH",
+		2: "0.08 WETH",
+		3: "0.083 WETH",
+		6: "0.082500 WETH",
+	} {
+		if got := a.Format(precision); got != want {
+			t.Errorf("%v.Format(%d) got %q; want %q", a, precision, got, want)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	one := WETH.Units(1)
+	half, err := WETH.Parse("0.5")
+	if err != nil {
+		t.Fatalf("%+v.Parse(0.5) error %v", WETH, err)
+	}
+
+	sum, err := one.Add(half)
+	if err != nil {
+		t.Fatalf("%v.Add(%v) error %v", one, half, err)
+	}
+	if got, want := sum.String(), "1.5 WETH"; got != want {
+		t.Errorf("%v.Add(%v) got %q; want %q", one, half, got, want)
+	}
+
+	diff, err := half.Sub(one)
+	if err != nil {
+		t.Fatalf("%v.Sub(%v) error %v", half, one, err)
+	}
+	if got, want := diff.String(), "-0.5 WETH"; got != want {
+		t.Errorf("%v.Sub(%v) got %q; want %q", half, one, got, want)
+	}
+	if diff.Sign() != -1 {
+		t.Errorf("%v.Sign() got %d; want -1", diff, diff.Sign())
+	}
+
+	if got, want := half.Mul(3).String(), "1.5 WETH"; got != want {
+		t.Errorf("%v.Mul(3) got %q; want %q", half, got, want)
+	}
+	if !half.Mul(3).Equal(sum) {
+		t.Errorf("%v.Mul(3).Equal(%v) got false; want true", half, sum)
+	}
+
+	if got, err := half.Cmp(one); err != nil || got != -1 {
+		t.Errorf("%v.Cmp(%v) got %d, err %v; want -1, nil", half, one, got, err)
+	}
+	if got, err := one.Cmp(one); err != nil || got != 0 {
+		t.Errorf("%v.Cmp(%v) got %d, err %v; want 0, nil", one, one, got, err)
+	}
+
+	if (Amount{}).Sign() != 0 || !(Amount{}).Equal(Amount{Value: new(big.Int)}) {
+		t.Errorf("zero Amount not equal to explicit zero Value")
+	}
+
+	// Operations must never modify their operands.
+	if got, want := one.String(), "1 WETH"; got != want {
+		t.Errorf("after arithmetic, operand got %q; want %q", got, want)
+	}
+
+	t.Run("mismatched tokens", func(t *testing.T) {
+		other := ETH.Units(1)
+		if _, err := one.Add(other); err == nil {
+			t.Errorf("%v.Add(%v) got nil error; want mismatched tokens", one, other)
+		}
+		if _, err := one.Sub(other); err == nil {
+			t.Errorf("%v.Sub(%v) got nil error; want mismatched tokens", one, other)
+		}
+		if _, err := one.Cmp(other); err == nil {
+			t.Errorf("%v.Cmp(%v) got nil error; want mismatched tokens", one, other)
+		}
+		if one.Equal(other) {
+			t.Errorf("%v.Equal(%v) got true; want false", one, other)
+		}
+	})
+}
+
+func TestJSON(t *testing.T) {
+	type sale struct {
+		Price Amount `json:"price"`
+	}
+
+	in := sale{Price: WETH.Amount(eth.EtherFraction(825, 10000))}
+	buf, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("json.Marshal(%+v) error %v", in, err)
+	}
+	if got, want := string(buf), `{"price":"82500000000000000"}`; got != want {
+		t.Errorf("json.Marshal(%+v) got %s; want %s", in, got, want)
+	}
+
+	got := sale{Price: Amount{Token: WETH}}
+	if err := json.Unmarshal(buf, &got); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error %v", buf, err)
+	}
+	if !got.Price.Equal(in.Price) {
+		t.Errorf("json.Unmarshal(json.Marshal(%v)) got %v", in.Price, got.Price)
+	}
+
+	t.Run("number", func(t *testing.T) {
+		var a Amount
+		if err := json.Unmarshal([]byte(`1000000000000000000000`), &a); err != nil {
+			t.Fatalf("json.Unmarshal([number]) error %v", err)
+		}
+		if want := eth.Ether(1000); a.Value.Cmp(want) != 0 {
+			t.Errorf("json.Unmarshal([number]) got %d; want %d", a.Value, want)
+		}
+	})
+
+	for _, bad := range []string{`"1.5"`, `1.5`, `true`, `"0x10"`} {
+		var a Amount
+		if err := json.Unmarshal([]byte(bad), &a); err == nil {
+			t.Errorf("json.Unmarshal(%s) got nil error; want error", bad)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package erc721
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A Bucketer converts non-string Attribute values into categorical values,
+// allowing them to be used in rarity calculations. Fit is called once for each
+// TraitType and OpenSeaDisplayType pair, with all non-string values of the
+// pair across a Collection, and returns a function to bucket each of them.
+type Bucketer interface {
+	Fit(values []interface{}) (func(interface{}) (string, error), error)
+}
+
+// ExactBuckets places each distinct value in its own bucket. Numbers are
+// formatted in their shortest representation, so equal values of different
+// numeric types share a bucket. Buckets are tagged with the value's type; e.g.
+// "number:7" and "bool:true", so they never collide with the equivalent string
+// values, "7" and "true", of the same TraitType.
+type ExactBuckets struct{}
+
+// Fit implements Bucketer.
+func (ExactBuckets) Fit([]interface{}) (func(interface{}) (string, error), error) {
+	return func(v interface{}) (string, error) {
+		if f, ok := numericValue(v); ok {
+			return "number:" + formatFloat(f), nil
+		}
+		if b, ok := v.(bool); ok {
+			return boolBucket(b), nil
+		}
+		return fmt.Sprintf("%T:%v", v, v), nil
+	}, nil
+}
+
+// BoolBuckets converts booleans to "bool:true" and "bool:false", as for
+// ExactBuckets, and rejects all other types.
+type BoolBuckets struct{}
+
+// Fit implements Bucketer.
+func (BoolBuckets) Fit([]interface{}) (func(interface{}) (string, error), error) {
+	return func(v interface{}) (string, error) {
+		b, ok := v.(bool)
+		if !ok {
+			return "", fmt.Errorf("%T requires bool; got %T", BoolBuckets{}, v)
+		}
+		return boolBucket(b), nil
+	}, nil
+}
+
+// boolBucket returns the type-tagged bucket of a boolean.
+func boolBucket(b bool) string {
+	return "bool:" + strconv.FormatBool(b)
+}
+
+// FixedWidthBuckets places numerical values into half-open intervals
+// [Origin + k*Width, Origin + (k+1)*Width) for integer k. Buckets are named by
+// their interval, tagged as for ExactBuckets; e.g. "number:[10, 20)".
+type FixedWidthBuckets struct {
+	Width, Origin float64
+}
+
+// Fit implements Bucketer.
+func (b FixedWidthBuckets) Fit([]interface{}) (func(interface{}) (string, error), error) {
+	if !(b.Width > 0) {
+		return nil, fmt.Errorf("%T.Width must be positive; got %v", b, b.Width)
+	}
+	return func(v interface{}) (string, error) {
+		f, ok := numericValue(v)
+		if !ok {
+			return "", fmt.Errorf("%T requires numbers; got %T", b, v)
+		}
+		k := math.Floor((f - b.Origin) / b.Width)
+		lo := b.Origin + k*b.Width
+		return fmt.Sprintf("number:[%s, %s)", formatFloat(lo), formatFloat(lo+b.Width)), nil
+	}, nil
+}
+
+// QuantileBuckets places numerical values into N buckets of approximately
+// equal population. Equal values always share a bucket, so there may be fewer
+// than N buckets if values are repeated. Buckets are named by their interval
+// of observed values, tagged as for ExactBuckets; e.g. "number:[10, 20)", with
+// the last bucket being closed.
+type QuantileBuckets struct {
+	N int
+}
+
+// Fit implements Bucketer.
+func (b QuantileBuckets) Fit(values []interface{}) (func(interface{}) (string, error), error) {
+	if b.N < 1 {
+		return nil, fmt.Errorf("%T.N must be positive; got %d", b, b.N)
+	}
+
+	nums := make([]float64, len(values))
+	for i, v := range values {
+		f, ok := numericValue(v)
+		if !ok {
+			return nil, fmt.Errorf("%T requires numbers; got %T", b, v)
+		}
+		nums[i] = f
+	}
+	if len(nums) == 0 {
+		return ExactBuckets{}.Fit(nil)
+	}
+	sort.Float64s(nums)
+
+	// Bucket i is [bounds[i], bounds[i+1]), except for the last bucket, which
+	// also includes the maximum value.
+	bounds := []float64{nums[0]}
+	for k := 1; k < b.N; k++ {
+		if q := nums[k*len(nums)/b.N]; q > bounds[len(bounds)-1] {
+			bounds = append(bounds, q)
+		}
+	}
+	max := nums[len(nums)-1]
+
+	return func(v interface{}) (string, error) {
+		f, ok := numericValue(v)
+		if !ok {
+			return "", fmt.Errorf("%T requires numbers; got %T", b, v)
+		}
+		// Index of the last lower bound <= f.
+		i := sort.Search(len(bounds), func(i int) bool { return bounds[i] > f }) - 1
+		if i < 0 {
+			i = 0
+		}
+		if i == len(bounds)-1 {
+			return fmt.Sprintf("number:[%s, %s]", formatFloat(bounds[i]), formatFloat(max)), nil
+		}
+		return fmt.Sprintf("number:[%s, %s)", formatFloat(bounds[i]), formatFloat(bounds[i+1])), nil
+	}, nil
+}
+
+// formatFloat returns f in its shortest decimal representation.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// ParseBucketer parses a Bucketer from its string representation, as used by
+// command-line flags:
+//
+//	exact
+//	bool
+//	width:<width>[:<origin>]
+//	quantile:<n>
+func ParseBucketer(s string) (Bucketer, error) {
+	parts := strings.Split(s, ":")
+	args := parts[1:]
+
+	parseFloat := func(i int) (float64, error) {
+		f, err := strconv.ParseFloat(args[i], 64)
+		if err != nil {
+			return 0, fmt.Errorf("bucketer %q: %v", s, err)
+		}
+		return f, nil
+	}
+
+	switch name := parts[0]; {
+	case name == "exact" && len(args) == 0:
+		return ExactBuckets{}, nil
+	case name == "bool" && len(args) == 0:
+		return BoolBuckets{}, nil
+	case name == "width" && (len(args) == 1 || len(args) == 2):
+		var (
+			b   FixedWidthBuckets
+			err error
+		)
+		if b.Width, err = parseFloat(0); err != nil {
+			return nil, err
+		}
+		if len(args) == 2 {
+			if b.Origin, err = parseFloat(1); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case name == "quantile" && len(args) == 1:
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("bucketer %q: %v", s, err)
+		}
+		return QuantileBuckets{N: n}, nil
+	default:
+		return nil, fmt.Errorf("unsupported bucketer %q; must be exact, bool, width:<width>[:<origin>] or quantile:<n>", s)
+	}
+}
+
+// Bucketers used by Bucketing for nil fields, in ParseBucketer() syntax. These
+// are also the defaults of the ethier rarity command.
+const (
+	DefaultNumericBuckets = "quantile:10"
+	DefaultDateBuckets    = "exact"
+	DefaultBuckets        = "exact"
+)
+
+// Bucketing selects a Bucketer for each non-string Attribute value based on its
+// TraitType and DisplayType. String values are never bucketed.
+type Bucketing struct {
+	// TraitTypes, if they contain an Attribute's TraitType, take precedence
+	// over all other fields.
+	TraitTypes map[string]Bucketer
+	// Numeric is used for values with DisplayNumber, DisplayBoostNumber and
+	// DisplayBoostPercentage display types; Date for DisplayDate; and Default
+	// for DisplayDefault. Nil Bucketers default to DefaultNumericBuckets,
+	// DefaultDateBuckets and DefaultBuckets respectively.
+	Numeric, Date, Default Bucketer
+}
+
+// bucketer returns the Bucketer to be used for the Attribute. A nil Bucketing
+// is equivalent to a zero Bucketing.
+func (b *Bucketing) bucketer(a *Attribute) Bucketer {
+	if b == nil {
+		b = new(Bucketing)
+	}
+	if tb, ok := b.TraitTypes[a.TraitType]; ok {
+		return tb
+	}
+
+	var bb Bucketer
+	var def string
+	switch a.DisplayType {
+	case DisplayNumber, DisplayBoostNumber, DisplayBoostPercentage:
+		bb, def = b.Numeric, DefaultNumericBuckets
+	case DisplayDate:
+		bb, def = b.Date, DefaultDateBuckets
+	default:
+		bb, def = b.Default, DefaultBuckets
+	}
+	if bb != nil {
+		return bb
+	}
+	bb, err := ParseBucketer(def)
+	if err != nil {
+		// The defaults are constants, tested to be valid.
+		panic(fmt.Sprintf("ParseBucketer(%q) error %v", def, err))
+	}
+	return bb
+}
+
+// BucketedTraits is equivalent to Traits() except that non-string values are
+// bucketed according to the Bucketing. A nil Bucketing uses the default
+// Bucketers; see Bucketing.
+func (coll Collection) BucketedTraits(b *Bucketing) (*Traits, error) {
+	type group struct {
+		traitType   string
+		displayType OpenSeaDisplayType
+	}
+	values := make(map[group][]interface{})
+	for _, meta := range coll {
+		if meta == nil {
+			continue
+		}
+		for _, a := range meta.Attributes {
+			if a == nil {
+				continue
+			}
+			if _, ok := a.Value.(string); ok {
+				continue
+			}
+			g := group{a.TraitType, a.DisplayType}
+			values[g] = append(values[g], a.Value)
+		}
+	}
+
+	fns := make(map[group]func(interface{}) (string, error))
+	for g, vals := range values {
+		a := &Attribute{TraitType: g.traitType, DisplayType: g.displayType}
+		fn, err := b.bucketer(a).Fit(vals)
+		if err != nil {
+			return nil, fmt.Errorf("trait type %q: %v", g.traitType, err)
+		}
+		fns[g] = fn
+	}
+
+	return coll.traits(func(a *Attribute) (string, error) {
+		s, err := fns[group{a.TraitType, a.DisplayType}](a.Value)
+		if err != nil {
+			return "", fmt.Errorf("trait type %q: %v", a.TraitType, err)
+		}
+		return s, nil
+	})
+}
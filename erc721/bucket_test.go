@@ -0,0 +1,169 @@
+package erc721
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+)
+
+func TestBucketers(t *testing.T) {
+	values := []interface{}{1, 2, 2, 3, 4, 5, 6, 7, 8, 9.5}
+
+	tests := []struct {
+		bucketer Bucketer
+		want     []string
+	}{
+		{
+			bucketer: ExactBuckets{},
+			want:     []string{"number:1", "number:2", "number:2", "number:3", "number:4", "number:5", "number:6", "number:7", "number:8", "number:9.5"},
+		},
+		{
+			bucketer: FixedWidthBuckets{Width: 5},
+			want:     []string{"number:[0, 5)", "number:[0, 5)", "number:[0, 5)", "number:[0, 5)", "number:[0, 5)", "number:[5, 10)", "number:[5, 10)", "number:[5, 10)", "number:[5, 10)", "number:[5, 10)"},
+		},
+		{
+			bucketer: FixedWidthBuckets{Width: 5, Origin: 2},
+			want:     []string{"number:[-3, 2)", "number:[2, 7)", "number:[2, 7)", "number:[2, 7)", "number:[2, 7)", "number:[2, 7)", "number:[2, 7)", "number:[7, 12)", "number:[7, 12)", "number:[7, 12)"},
+		},
+		{
+			bucketer: QuantileBuckets{N: 2},
+			want:     []string{"number:[1, 5)", "number:[1, 5)", "number:[1, 5)", "number:[1, 5)", "number:[1, 5)", "number:[5, 9.5]", "number:[5, 9.5]", "number:[5, 9.5]", "number:[5, 9.5]", "number:[5, 9.5]"},
+		},
+		{
+			// The repeated 2 results in a single boundary, so there are only
+			// 9 buckets.
+			bucketer: QuantileBuckets{N: 10},
+			want:     []string{"number:[1, 2)", "number:[2, 3)", "number:[2, 3)", "number:[3, 4)", "number:[4, 5)", "number:[5, 6)", "number:[6, 7)", "number:[7, 8)", "number:[8, 9.5)", "number:[9.5, 9.5]"},
+		},
+	}
+
+	for _, tt := range tests {
+		fn, err := tt.bucketer.Fit(values)
+		if err != nil {
+			t.Fatalf("%T.Fit() error %v", tt.bucketer, err)
+		}
+		var got []string
+		for _, v := range values {
+			b, err := fn(v)
+			if err != nil {
+				t.Fatalf("%+v.Fit()(%v) error %v", tt.bucketer, v, err)
+			}
+			got = append(got, b)
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("%+v buckets diff (-want +got):\n%s", tt.bucketer, diff)
+		}
+	}
+}
+
+func TestParseBucketer(t *testing.T) {
+	tests := []struct {
+		in             string
+		want           Bucketer
+		errDiffAgainst interface{}
+	}{
+		{in: "exact", want: ExactBuckets{}},
+		{in: "bool", want: BoolBuckets{}},
+		{in: "width:10", want: FixedWidthBuckets{Width: 10}},
+		{in: "width:0.5:0.25", want: FixedWidthBuckets{Width: 0.5, Origin: 0.25}},
+		{in: "quantile:4", want: QuantileBuckets{N: 4}},
+		{in: "quantile", errDiffAgainst: "unsupported bucketer"},
+		{in: "width:x", errDiffAgainst: "invalid syntax"},
+		{in: "exact:1", errDiffAgainst: "unsupported bucketer"},
+		{in: "median", errDiffAgainst: "unsupported bucketer"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseBucketer(tt.in)
+		if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+			t.Errorf("ParseBucketer(%q) %s", tt.in, diff)
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("ParseBucketer(%q) diff (-want +got):\n%s", tt.in, diff)
+		}
+	}
+}
+
+func TestBucketedTraits(t *testing.T) {
+	const collJSON = `[
+		{"attributes":[{"trait_type":"Level","value":1,"display_type":"number"},{"trait_type":"Shiny","value":true},{"trait_type":"Edition","value":7}]},
+		{"attributes":[{"trait_type":"Level","value":12,"display_type":"number"},{"trait_type":"Shiny","value":false},{"trait_type":"Edition","value":7}]},
+		{"attributes":[{"trait_type":"Level","value":15,"display_type":"number"},{"trait_type":"Shiny","value":"true"}]}
+	]`
+	var md []*Metadata
+	if err := json.Unmarshal([]byte(collJSON), &md); err != nil {
+		t.Fatalf("json.Unmarshal() error %v", err)
+	}
+	coll := CollectionFromMetadata(md)
+
+	got, err := coll.BucketedTraits(&Bucketing{
+		Numeric:    FixedWidthBuckets{Width: 10},
+		TraitTypes: map[string]Bucketer{"Shiny": BoolBuckets{}},
+	})
+	if err != nil {
+		t.Fatalf("%T.BucketedTraits() error %v", coll, err)
+	}
+
+	want := map[string]map[string]int{
+		"Level":   {"number:[0, 10)": 1, "number:[10, 20)": 2},
+		"Shiny":   {"bool:true": 1, "bool:false": 1, "true": 1},
+		"Edition": {"number:7": 2},
+	}
+	if diff := cmp.Diff(want, got.Counts); diff != "" {
+		t.Errorf("%T.BucketedTraits().Counts diff (-want +got):\n%s", coll, diff)
+	}
+
+	if _, err := coll.BucketedTraits(&Bucketing{Default: BoolBuckets{}}); err == nil {
+		t.Errorf("%T.BucketedTraits(BoolBuckets for numeric Edition) got nil error; want error", coll)
+	}
+
+	t.Run("range and string values", func(t *testing.T) {
+		coll := Collection{
+			*TokenIDFromInt(0): {Attributes: []*Attribute{{TraitType: "Level", Value: 5, DisplayType: DisplayNumber}}},
+			*TokenIDFromInt(1): {Attributes: []*Attribute{{TraitType: "Level", Value: "[0, 10)"}}},
+		}
+		got, err := coll.BucketedTraits(&Bucketing{Numeric: FixedWidthBuckets{Width: 10}})
+		if err != nil {
+			t.Fatalf("%T.BucketedTraits() error %v", coll, err)
+		}
+		want := map[string]map[string]int{
+			"Level": {"number:[0, 10)": 1, "[0, 10)": 1},
+		}
+		if diff := cmp.Diff(want, got.Counts); diff != "" {
+			t.Errorf("%T.BucketedTraits().Counts diff (-want +got):\n%s", coll, diff)
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		var explicit Bucketing
+		for _, b := range []struct {
+			dst *Bucketer
+			s   string
+		}{
+			{&explicit.Numeric, DefaultNumericBuckets},
+			{&explicit.Date, DefaultDateBuckets},
+			{&explicit.Default, DefaultBuckets},
+		} {
+			var err error
+			if *b.dst, err = ParseBucketer(b.s); err != nil {
+				t.Fatalf("ParseBucketer(%q) error %v", b.s, err)
+			}
+		}
+		want, err := coll.BucketedTraits(&explicit)
+		if err != nil {
+			t.Fatalf("%T.BucketedTraits(%+v) error %v", coll, explicit, err)
+		}
+
+		for _, b := range []*Bucketing{nil, {}} {
+			got, err := coll.BucketedTraits(b)
+			if err != nil {
+				t.Fatalf("%T.BucketedTraits(%+v) error %v", coll, b, err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("%T.BucketedTraits(%+v) diff (-explicit defaults +got):\n%s", coll, b, diff)
+			}
+		}
+	})
+}
@@ -0,0 +1,288 @@
+package erc721
+
+import (
+	"container/list"
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// CacheConfig configures caching by a Server. A nil CacheConfig disables all
+// caching, and the zero value only enables ETag support.
+type CacheConfig struct {
+	// MaxTokens limits the number of tokens for which existence checks (via
+	// Contract.OwnerOf) are cached; if zero, existence isn't cached. Tokens
+	// only ever transition from not minted to minted so positive results are
+	// cached until evicted by the size limit.
+	MaxTokens int
+	// NotMintedTTL is the duration for which negative existence checks are
+	// cached. If zero, they aren't cached, which is required for reveals to
+	// occur immediately after minting.
+	NotMintedTTL time.Duration
+
+	// MaxResponses limits the number of memoised metadata JSON and image
+	// responses; if zero, responses aren't memoised and every request results
+	// in a call to the respective handler.
+	MaxResponses int
+	// ResponseTTL is the duration for which responses are memoised. If zero,
+	// they are memoised until evicted by the size limit.
+	ResponseTTL time.Duration
+
+	// CacheControl, if non-empty, is sent as the Cache-Control header of all
+	// successful token-data responses; e.g. "public, max-age=3600".
+	CacheControl string
+}
+
+// A tokenDataResponse is a fully buffered successful response.
+type tokenDataResponse struct {
+	body        []byte
+	contentType string
+	etag        string
+}
+
+// newTokenDataResponse returns a tokenDataResponse with its ETag computed from
+// the body.
+func newTokenDataResponse(body []byte, contentType string) *tokenDataResponse {
+	h := crypto.Keccak256(body)
+	return &tokenDataResponse{
+		body:        body,
+		contentType: contentType,
+		etag:        `"` + hex.EncodeToString(h[:16]) + `"`,
+	}
+}
+
+// notModified reports whether the request's If-None-Match header matches the
+// response's ETag. Weak comparison is used, as per RFC 7232 Section 3.2.
+func (resp *tokenDataResponse) notModified(r *http.Request) bool {
+	inm := r.Header.Get("If-None-Match")
+	if inm == "" {
+		return false
+	}
+	for _, tag := range strings.Split(inm, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == resp.etag {
+			return true
+		}
+	}
+	return false
+}
+
+// write writes the response to w, or a 304 (Not Modified) if r has a matching
+// If-None-Match header.
+func (resp *tokenDataResponse) write(w http.ResponseWriter, r *http.Request, cacheControl string) error {
+	h := w.Header()
+	h.Set("ETag", resp.etag)
+	if cacheControl != "" {
+		h.Set("Cache-Control", cacheControl)
+	}
+	if resp.notModified(r) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	h.Set("Content-Type", resp.contentType)
+	if _, err := w.Write(resp.body); err != nil {
+		return errorf(500, "write response: %v", err)
+	}
+	return nil
+}
+
+// A serverCache holds the cached state of a Server.
+type serverCache struct {
+	cfg       CacheConfig
+	minted    *lruCache
+	responses *lruCache
+}
+
+// newServerCache returns a serverCache configured by cfg, using now() as its
+// clock. It returns nil if cfg is nil, which is a valid, albeit always empty,
+// cache.
+func newServerCache(cfg *CacheConfig, now func() time.Time) *serverCache {
+	if cfg == nil {
+		return nil
+	}
+	return &serverCache{
+		cfg:       *cfg,
+		minted:    newLRUCache(cfg.MaxTokens, now),
+		responses: newLRUCache(cfg.MaxResponses, now),
+	}
+}
+
+// isMinted returns whether the token is known to be minted (or known to not be),
+// and whether the value was cached.
+func (c *serverCache) isMinted(id string) (minted bool, ok bool) {
+	if c == nil {
+		return false, false
+	}
+	v, ok := c.minted.get(id)
+	if !ok {
+		return false, false
+	}
+	return v.(bool), true
+}
+
+// setMinted caches the existence of the token.
+func (c *serverCache) setMinted(id string, minted bool) {
+	switch {
+	case c == nil:
+	case minted:
+		c.minted.put(id, true, 0)
+	case c.cfg.NotMintedTTL > 0:
+		c.minted.put(id, false, c.cfg.NotMintedTTL)
+	}
+}
+
+// responseKey returns the key under which a response is memoised. It is
+// prefixed with the token ID to allow for invalidation of all of a token's
+// responses.
+func responseKey(id *TokenID, r *http.Request) string {
+	return id.String() + " " + r.URL.Path
+}
+
+// invalidate removes all memoised responses for tokens for which fn returns
+// true.
+func (c *serverCache) invalidate(fn func(*TokenID) bool) {
+	if c == nil {
+		return
+	}
+	c.responses.removeIf(func(key string) bool {
+		rawID, _, _ := strings.Cut(key, " ")
+		b, ok := new(big.Int).SetString(rawID, 10)
+		if !ok {
+			return false
+		}
+		id, err := TokenIDFromBig(b)
+		return err == nil && fn(id)
+	})
+}
+
+// response returns the memoised response, if one exists.
+func (c *serverCache) response(key string) (*tokenDataResponse, bool) {
+	if c == nil {
+		return nil, false
+	}
+	v, ok := c.responses.get(key)
+	if !ok {
+		return nil, false
+	}
+	return v.(*tokenDataResponse), true
+}
+
+// setResponse memoises the response.
+func (c *serverCache) setResponse(key string, resp *tokenDataResponse) {
+	if c == nil {
+		return
+	}
+	c.responses.put(key, resp, c.cfg.ResponseTTL)
+}
+
+// An lruCache is a concurrency-safe, size-limited, least-recently-used cache
+// with optional per-entry expiry.
+type lruCache struct {
+	max int
+	now func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List // of *lruEntry, most recently used at the front
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	value   interface{}
+	expires time.Time // zero for no expiry
+}
+
+// newLRUCache returns an lruCache holding at most max entries. If max is zero,
+// the cache is always empty.
+func newLRUCache(max int, now func() time.Time) *lruCache {
+	return &lruCache{
+		max:     max,
+		now:     now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// get returns the unexpired value stored under the key, if one exists.
+func (c *lruCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*lruEntry)
+	if !e.expires.IsZero() && !c.now().Before(e.expires) {
+		c.removeElement(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return e.value, true
+}
+
+// put stores the value under the key, evicting the least-recently used entry
+// if necessary. If ttl is non-zero, the entry expires after it has elapsed.
+func (c *lruCache) put(key string, value interface{}, ttl time.Duration) {
+	if c.max <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := &lruEntry{key: key, value: value}
+	if ttl > 0 {
+		e.expires = c.now().Add(ttl)
+	}
+
+	if el, ok := c.entries[key]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(e)
+	for c.order.Len() > c.max {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// removeIf removes all entries with keys for which fn returns true.
+func (c *lruCache) removeIf(fn func(key string) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, el := range c.entries {
+		if fn(key) {
+			c.removeElement(el)
+		}
+	}
+}
+
+// len returns the number of entries, including expired ones that are yet to be
+// removed.
+func (c *lruCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// removeElement removes the element from the cache. The caller MUST hold c.mu.
+func (c *lruCache) removeElement(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*lruEntry).key)
+}
+
+// InvalidateMetadata removes all memoised metadata and image responses for
+// tokens affected by the update, such that they are regenerated by the
+// respective handlers on the next request. It is a MetadataUpdateHandler,
+// typically passed to NewMetadataUpdateWatcher(), and is a no-op if s.Cache is
+// nil.
+func (s *Server) InvalidateMetadata(u MetadataUpdate) {
+	s.cache.invalidate(u.Contains)
+}
@@ -0,0 +1,285 @@
+package erc721
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/julienschmidt/httprouter"
+)
+
+// fakeContract is an Interface for which tokens [0, totalSupply) exist. It
+// records the number of calls to OwnerOf().
+type fakeContract struct {
+	mu          sync.Mutex
+	totalSupply int64
+	ownerOf     int
+}
+
+func (c *fakeContract) OwnerOf(_ *bind.CallOpts, id *big.Int) (common.Address, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ownerOf++
+	if id.Cmp(big.NewInt(c.totalSupply)) >= 0 {
+		return common.Address{}, errors.New("nonexistent token")
+	}
+	return common.Address{1}, nil
+}
+
+func (c *fakeContract) mint(n int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.totalSupply += n
+}
+
+func (c *fakeContract) calls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ownerOf
+}
+
+// fakeClock is a manually advanced clock for use as Server.now.
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+// countingServer returns a Server with metadata and image endpoints that count
+// their respective calls.
+func countingServer(contract Interface, cfg *CacheConfig, clock *fakeClock) (*Server, *int, *int) {
+	var mdCalls, imgCalls int
+	srv := &Server{
+		Contract: contract,
+		Metadata: []MetadataEndpoint{{
+			Path: "/metadata/:tokenId",
+			Handler: func(_ Interface, id *TokenID, _ httprouter.Params) (*Metadata, int, error) {
+				mdCalls++
+				return &Metadata{Name: fmt.Sprintf("Token %s", id)}, 200, nil
+			},
+		}},
+		Image: []ImageEndpoint{{
+			Path: "/image/:tokenId",
+			Handler: func(_ Interface, id *TokenID, _ httprouter.Params) (io.Reader, string, int, error) {
+				imgCalls++
+				return strings.NewReader(fmt.Sprintf("Image %s", id)), "image/png", 200, nil
+			},
+		}},
+		Cache: cfg,
+		now:   clock.now,
+	}
+	return srv, &mdCalls, &imgCalls
+}
+
+// httpGetWithHeaders is equivalent to httpGet() but with additional request
+// headers.
+func httpGetWithHeaders(t *testing.T, url string, headers map[string]string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest(GET, %q): %v", url, err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("HTTP GET %q: %v", url, err)
+	}
+	return res
+}
+
+func TestServerCachesExistence(t *testing.T) {
+	contract := &fakeContract{totalSupply: 2}
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	srv, _, _ := countingServer(contract, &CacheConfig{
+		MaxTokens:    2,
+		NotMintedTTL: time.Minute,
+	}, clock)
+	baseURL := start(t, srv)
+
+	get := func(t *testing.T, id, wantCode, wantOwnerOfCalls int) {
+		t.Helper()
+		url := fmt.Sprintf("%s/metadata/%d", baseURL, id)
+		resp := httpGet(t, url)
+		resp.Body.Close()
+		if got := resp.StatusCode; got != wantCode {
+			t.Errorf("HTTP GET %q got code %d; want %d", url, got, wantCode)
+		}
+		if got := contract.calls(); got != wantOwnerOfCalls {
+			t.Errorf("After HTTP GET %q; got %d total calls to OwnerOf(); want %d", url, got, wantOwnerOfCalls)
+		}
+	}
+
+	get(t, 0, 200, 1)
+	get(t, 0, 200, 1)
+	get(t, 1, 200, 2)
+	get(t, 1, 200, 2)
+
+	// Negative results are cached for NotMintedTTL, so minting isn't detected
+	// until it elapses.
+	get(t, 2, 404, 3)
+	contract.mint(1)
+	get(t, 2, 404, 3)
+	clock.advance(time.Minute)
+	get(t, 2, 200, 4)
+	get(t, 2, 200, 4)
+
+	// MaxTokens = 2 so token 0 was evicted as least recently used.
+	get(t, 0, 200, 5)
+	if got, want := srv.cache.minted.len(), 2; got != want {
+		t.Errorf("%T.cache.minted.len() got %d; want %d", srv, got, want)
+	}
+}
+
+func TestServerMemoisesResponses(t *testing.T) {
+	contract := &fakeContract{totalSupply: 10}
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	srv, mdCalls, imgCalls := countingServer(contract, &CacheConfig{
+		MaxResponses: 3,
+		ResponseTTL:  time.Hour,
+		CacheControl: "public, max-age=3600",
+	}, clock)
+	baseURL := start(t, srv)
+
+	get := func(t *testing.T, path, wantBody string) *http.Response {
+		t.Helper()
+		url := baseURL + path
+		resp := httpGet(t, url)
+		defer resp.Body.Close()
+
+		if got, want := resp.StatusCode, 200; got != want {
+			t.Fatalf("HTTP GET %q got code %d; want %d", url, got, want)
+		}
+		got, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("io.ReadAll([http response body]): %v", err)
+		}
+		if string(got) != wantBody {
+			t.Errorf("HTTP GET %q got body %q; want %q", url, got, wantBody)
+		}
+		if got, want := resp.Header.Get("Cache-Control"), "public, max-age=3600"; got != want {
+			t.Errorf("HTTP GET %q got Cache-Control %q; want %q", url, got, want)
+		}
+		if resp.Header.Get("ETag") == "" {
+			t.Errorf("HTTP GET %q got empty ETag", url)
+		}
+		return resp
+	}
+
+	md1 := fmt.Sprintf(`{"name":"Token 1","image":"%s/image/1"}`, baseURL)
+	wantCalls := func(t *testing.T, wantMD, wantImg int) {
+		t.Helper()
+		if *mdCalls != wantMD || *imgCalls != wantImg {
+			t.Errorf("got %d metadata and %d image handler calls; want %d and %d", *mdCalls, *imgCalls, wantMD, wantImg)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		get(t, "/metadata/1", md1)
+		get(t, "/image/1", "Image 1")
+	}
+	wantCalls(t, 1, 1)
+
+	// Expiry
+	clock.advance(time.Hour)
+	get(t, "/image/1", "Image 1")
+	wantCalls(t, 1, 2)
+
+	// Eviction: MaxResponses = 3 so the least-recently used /metadata/1 is
+	// evicted.
+	get(t, "/image/2", "Image 2")
+	get(t, "/image/3", "Image 3")
+	get(t, "/metadata/1", md1)
+	wantCalls(t, 2, 4)
+}
+
+func TestServerETag(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		cfg  *CacheConfig
+	}{
+		{name: "without memoisation", cfg: &CacheConfig{}},
+		{name: "with memoisation", cfg: &CacheConfig{MaxResponses: 10}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			contract := &fakeContract{totalSupply: 10}
+			srv, _, imgCalls := countingServer(contract, tt.cfg, &fakeClock{})
+			url := start(t, srv) + "/image/7"
+
+			resp := httpGet(t, url)
+			resp.Body.Close()
+			etag := resp.Header.Get("ETag")
+			if etag == "" {
+				t.Fatalf("HTTP GET %q got empty ETag", url)
+			}
+
+			tests := []struct {
+				ifNoneMatch string
+				wantCode    int
+			}{
+				{ifNoneMatch: etag, wantCode: http.StatusNotModified},
+				{ifNoneMatch: "W/" + etag, wantCode: http.StatusNotModified},
+				{ifNoneMatch: `"foo", ` + etag, wantCode: http.StatusNotModified},
+				{ifNoneMatch: "*", wantCode: http.StatusNotModified},
+				{ifNoneMatch: `"foo"`, wantCode: http.StatusOK},
+				{ifNoneMatch: "", wantCode: http.StatusOK},
+			}
+			for _, tt := range tests {
+				resp := httpGetWithHeaders(t, url, map[string]string{"If-None-Match": tt.ifNoneMatch})
+				resp.Body.Close()
+				if got := resp.StatusCode; got != tt.wantCode {
+					t.Errorf("HTTP GET %q with If-None-Match %q got code %d; want %d", url, tt.ifNoneMatch, got, tt.wantCode)
+				}
+				if got := resp.Header.Get("ETag"); got != etag {
+					t.Errorf("HTTP GET %q with If-None-Match %q got ETag %q; want %q", url, tt.ifNoneMatch, got, etag)
+				}
+			}
+
+			if tt.cfg.MaxResponses > 0 && *imgCalls != 1 {
+				t.Errorf("got %d image handler calls; want 1", *imgCalls)
+			}
+		})
+	}
+}
+
+func TestServerWithoutCache(t *testing.T) {
+	contract := &fakeContract{totalSupply: 1}
+	srv, mdCalls, _ := countingServer(contract, nil, &fakeClock{})
+	url := start(t, srv) + "/metadata/0"
+
+	for i := 0; i < 3; i++ {
+		resp := httpGet(t, url)
+		resp.Body.Close()
+		if resp.Header.Get("ETag") != "" {
+			t.Errorf("HTTP GET %q without %T got non-empty ETag", url, srv.Cache)
+		}
+	}
+	if got, want := contract.calls(), 3; got != want {
+		t.Errorf("got %d calls to OwnerOf(); want %d", got, want)
+	}
+	if got, want := *mdCalls, 3; got != want {
+		t.Errorf("got %d metadata handler calls; want %d", got, want)
+	}
+}
@@ -0,0 +1,152 @@
+package erc721
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadCollectionDir loads a Collection from a directory containing one JSON
+// file of Metadata per token. Each file's name is its token ID, in decimal or
+// 0x-prefixed hexadecimal, with an optional .json extension; e.g. 1, 1.json,
+// 0x1 or 0x1.json. Subdirectories, hidden files and files with any other
+// extension are ignored, but an otherwise eligible file with a name that can't
+// be parsed as a TokenID is an error.
+func LoadCollectionDir(dir string) (Collection, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadDir(%q): %v", dir, err)
+	}
+
+	coll := make(Collection)
+	for _, e := range entries {
+		name := e.Name()
+		ext := filepath.Ext(name)
+		if !e.Type().IsRegular() || strings.HasPrefix(name, ".") || (ext != "" && ext != ".json") {
+			continue
+		}
+
+		id := new(TokenID)
+		if err := id.UnmarshalText([]byte(strings.TrimSuffix(name, ext))); err != nil {
+			return nil, fmt.Errorf("file %q: %v", name, err)
+		}
+		if _, ok := coll[*id]; ok {
+			return nil, fmt.Errorf("file %q: duplicate token %s", name, id)
+		}
+
+		path := filepath.Join(dir, name)
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("os.ReadFile(%q): %v", path, err)
+		}
+		md := new(Metadata)
+		if err := json.Unmarshal(buf, md); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal(%q, %T): %v", path, md, err)
+		}
+		coll[*id] = md
+	}
+	return coll, nil
+}
+
+// ReadCollectionJSON reads a Collection from a JSON array of Metadata objects,
+// each of which carries its token ID in the idField field. See
+// ReadCollectionJSONL() for the treatment of idField.
+func ReadCollectionJSON(r io.Reader, idField string) (Collection, error) {
+	var objs []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&objs); err != nil {
+		return nil, fmt.Errorf("json.Decode(%T): %v", objs, err)
+	}
+
+	coll := make(Collection)
+	for i, obj := range objs {
+		if err := coll.addWithIDField(obj, idField); err != nil {
+			return nil, fmt.Errorf("index %d: %v", i, err)
+		}
+	}
+	return coll, nil
+}
+
+// ReadCollectionJSONL reads a Collection from JSON Lines, each line being a
+// Metadata object carrying its token ID in the idField field. Blank lines are
+// ignored. Token IDs may be JSON numbers or strings; strings are parsed as
+// decimal, or hexadecimal if 0x-prefixed. Unless idField is also a standard
+// Metadata field, it is removed from the Metadata's Extra fields.
+func ReadCollectionJSONL(r io.Reader, idField string) (Collection, error) {
+	coll := make(Collection)
+
+	// bufio.Scanner has a maximum token size that may be exceeded by metadata
+	// with inline images, so lines are read directly.
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		buf, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if b := bytes.TrimSpace(buf); len(b) > 0 {
+			if err := coll.addWithIDField(b, idField); err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+		}
+		if err == io.EOF {
+			return coll, nil
+		}
+	}
+}
+
+// addWithIDField parses the JSON object as Metadata and adds it to the
+// Collection, keyed by the token ID in its idField field.
+func (coll Collection) addWithIDField(obj []byte, idField string) error {
+	if idField == "" {
+		return fmt.Errorf("empty ID field name")
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(obj, &fields); err != nil {
+		return fmt.Errorf("json.Unmarshal(%T): %v", fields, err)
+	}
+	raw, ok := fields[idField]
+	if !ok {
+		return fmt.Errorf("missing ID field %q", idField)
+	}
+	id, err := tokenIDFromJSON(raw)
+	if err != nil {
+		return fmt.Errorf("ID field %q: %v", idField, err)
+	}
+	if _, ok := coll[*id]; ok {
+		return fmt.Errorf("duplicate token %s", id)
+	}
+
+	md := new(Metadata)
+	if err := json.Unmarshal(obj, md); err != nil {
+		return fmt.Errorf("json.Unmarshal(%T): %v", md, err)
+	}
+	delete(md.Extra, idField)
+	if len(md.Extra) == 0 {
+		md.Extra = nil
+	}
+
+	coll[*id] = md
+	return nil
+}
+
+// tokenIDFromJSON parses a TokenID from a JSON number or string.
+func tokenIDFromJSON(raw json.RawMessage) (*TokenID, error) {
+	text := []byte(raw)
+	if len(raw) > 0 && raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, err
+		}
+		text = []byte(s)
+	}
+	id := new(TokenID)
+	if err := id.UnmarshalText(text); err != nil {
+		return nil, err
+	}
+	return id, nil
+}
@@ -0,0 +1,192 @@
+package erc721
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+)
+
+func TestLoadCollectionDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string]string{
+		"1":          `{"name":"One"}`,
+		"2.json":     `{"name":"Two"}`,
+		"0x10":       `{"name":"Sixteen"}`,
+		"0x11.json":  `{"name":"Seventeen","custom":true}`,
+		"README.md":  `Ignored`,
+		".DS_Store":  `Ignored`,
+		"sub/3.json": `{"name":"Ignored"}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("os.MkdirAll(%q) error %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("os.WriteFile(%q) error %v", path, err)
+		}
+	}
+
+	got, err := LoadCollectionDir(dir)
+	if err != nil {
+		t.Fatalf("LoadCollectionDir() error %v", err)
+	}
+	want := Collection{
+		*TokenIDFromInt(1):  {Name: "One"},
+		*TokenIDFromInt(2):  {Name: "Two"},
+		*TokenIDFromInt(16): {Name: "Sixteen"},
+		*TokenIDFromInt(17): {
+			Name:  "Seventeen",
+			Extra: map[string]json.RawMessage{"custom": json.RawMessage("true")},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LoadCollectionDir() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadCollectionDirErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          map[string]string
+		errDiffAgainst interface{}
+	}{
+		{
+			name:           "invalid token ID",
+			files:          map[string]string{"one.json": `{}`},
+			errDiffAgainst: `file "one.json"`,
+		},
+		{
+			name: "duplicate token ID",
+			files: map[string]string{
+				"16.json":   `{}`,
+				"0x10.json": `{}`,
+			},
+			errDiffAgainst: "duplicate token 16",
+		},
+		{
+			name:           "invalid JSON",
+			files:          map[string]string{"1": `{`},
+			errDiffAgainst: "json.Unmarshal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, contents := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+					t.Fatalf("os.WriteFile() error %v", err)
+				}
+			}
+			_, err := LoadCollectionDir(dir)
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Errorf("LoadCollectionDir() %s", diff)
+			}
+		})
+	}
+}
+
+func TestReadCollection(t *testing.T) {
+	want := Collection{
+		*TokenIDFromInt(1):   {Name: "One"},
+		*TokenIDFromInt(2):   {Name: "Two"},
+		*TokenIDFromInt(255): {Name: "Hex", Extra: map[string]json.RawMessage{"other": json.RawMessage(`"kept"`)}},
+	}
+
+	tests := []struct {
+		name string
+		read func(string, string) (Collection, error)
+		in   string
+	}{
+		{
+			name: "JSONL",
+			read: func(in, idField string) (Collection, error) {
+				return ReadCollectionJSONL(strings.NewReader(in), idField)
+			},
+			in: `{"token_id":1,"name":"One"}
+{"token_id":"2","name":"Two"}
+
+{"token_id":"0xff","name":"Hex","other":"kept"}`,
+		},
+		{
+			name: "JSON",
+			read: func(in, idField string) (Collection, error) {
+				return ReadCollectionJSON(strings.NewReader(in), idField)
+			},
+			in: `[
+				{"name":"Hex","token_id":"0xff","other":"kept"},
+				{"name":"Two","token_id":2},
+				{"name":"One","token_id":"1"}
+			]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.read(tt.in, "token_id")
+			if err != nil {
+				t.Fatalf("Read%s() error %v", tt.name, err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Read%s() diff (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestReadCollectionJSONLErrors(t *testing.T) {
+	tests := []struct {
+		name, in, idField string
+		errDiffAgainst    interface{}
+	}{
+		{
+			name:           "empty ID field name",
+			in:             `{"id":1}`,
+			errDiffAgainst: "empty ID field",
+		},
+		{
+			name:           "missing ID",
+			in:             "{\"id\":1}\n{\"name\":\"x\"}",
+			idField:        "id",
+			errDiffAgainst: `line 2: missing ID field "id"`,
+		},
+		{
+			name:           "non-integer ID",
+			in:             `{"id":1.5}`,
+			idField:        "id",
+			errDiffAgainst: "invalid",
+		},
+		{
+			name:           "negative ID",
+			in:             `{"id":"-1"}`,
+			idField:        "id",
+			errDiffAgainst: "invalid",
+		},
+		{
+			name:           "duplicate ID",
+			in:             "{\"id\":10}\n{\"id\":\"0xa\"}",
+			idField:        "id",
+			errDiffAgainst: "line 2: duplicate token 10",
+		},
+		{
+			name:           "not an object",
+			in:             `[]`,
+			idField:        "id",
+			errDiffAgainst: "line 1: json.Unmarshal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadCollectionJSONL(strings.NewReader(tt.in), tt.idField)
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Errorf("ReadCollectionJSONL(%q, %q) %s", tt.in, tt.idField, diff)
+			}
+		})
+	}
+}
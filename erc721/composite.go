@@ -0,0 +1,227 @@
+package erc721
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/fs"
+	"path"
+	"sync"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// An ImageFormat is an encoding of images rendered by a LayerCompositor.
+type ImageFormat int
+
+// Supported ImageFormats.
+const (
+	PNGFormat ImageFormat = iota
+	// BMPFormat is a 24-bit BMP, encoded identically to
+	// contracts/utils/BMP.sol. See EncodeBMP().
+	BMPFormat
+)
+
+// ContentType returns the MIME type of the ImageFormat.
+func (f ImageFormat) ContentType() string {
+	switch f {
+	case PNGFormat:
+		return "image/png"
+	case BMPFormat:
+		return "image/bmp"
+	default:
+		return ""
+	}
+}
+
+// Encode writes img to w in the ImageFormat.
+func (f ImageFormat) Encode(w io.Writer, img image.Image) error {
+	switch f {
+	case PNGFormat:
+		return png.Encode(w, img)
+	case BMPFormat:
+		return EncodeBMP(w, img)
+	default:
+		return fmt.Errorf("unsupported %T(%d)", f, f)
+	}
+}
+
+// A LayerCompositor renders token images by compositing PNG layers, one for
+// each trait, in the manner of typical generative-art collections. Decoded
+// layers are cached, so a LayerCompositor can efficiently render images on
+// demand; see LayerCompositor.ImageHandler().
+type LayerCompositor struct {
+	// FS contains the layer files; typically os.DirFS(<layer directory>).
+	FS fs.FS
+	// Layers are the TraitTypes to be composited, from bottom to top. Tokens
+	// lacking a TraitType are rendered without its layer, and TraitTypes not
+	// in Layers are ignored.
+	Layers []string
+	// Path returns the path, within FS, of the layer for a trait value. If
+	// nil, DefaultLayerPath is used.
+	Path func(traitType, value string) string
+	// Background, if non-nil, is drawn beneath all layers. Otherwise the
+	// background is transparent, which is rendered as black by BMPFormat.
+	Background color.Color
+	// Format is the ImageFormat of images returned by ImageHandler().
+	Format ImageFormat
+
+	mu     sync.Mutex
+	layers map[string]image.Image
+}
+
+// DefaultLayerPath returns <traitType>/<value>.png.
+func DefaultLayerPath(traitType, value string) string {
+	return path.Join(traitType, value+".png")
+}
+
+// Composite returns the image of the token described by the Metadata. All
+// layers MUST have identical bounds.
+func (c *LayerCompositor) Composite(md *Metadata) (image.Image, error) {
+	values := make(map[string]string)
+	for _, a := range md.Attributes {
+		if a == nil {
+			continue
+		}
+		if s, ok := a.Value.(string); ok {
+			values[a.TraitType] = s
+		} else {
+			values[a.TraitType] = fmt.Sprint(a.Value)
+		}
+	}
+
+	var canvas *image.RGBA
+	for _, tt := range c.Layers {
+		v, ok := values[tt]
+		if !ok {
+			continue
+		}
+		layer, err := c.layer(tt, v)
+		if err != nil {
+			return nil, err
+		}
+
+		if canvas == nil {
+			canvas = image.NewRGBA(layer.Bounds())
+			if c.Background != nil {
+				draw.Draw(canvas, canvas.Bounds(), image.NewUniform(c.Background), image.Point{}, draw.Src)
+			}
+		} else if b := layer.Bounds(); b != canvas.Bounds() {
+			return nil, fmt.Errorf("layer %q=%q bounds %v differ from %v", tt, v, b, canvas.Bounds())
+		}
+		draw.Draw(canvas, canvas.Bounds(), layer, canvas.Bounds().Min, draw.Over)
+	}
+
+	if canvas == nil {
+		return nil, fmt.Errorf("no layers for any of %d TraitTypes", len(c.Layers))
+	}
+	return canvas, nil
+}
+
+// layer returns the decoded PNG layer for the trait value, reading it from
+// c.FS only if it isn't already cached.
+func (c *LayerCompositor) layer(traitType, value string) (image.Image, error) {
+	pathFn := c.Path
+	if pathFn == nil {
+		pathFn = DefaultLayerPath
+	}
+	p := pathFn(traitType, value)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if img, ok := c.layers[p]; ok {
+		return img, nil
+	}
+
+	f, err := c.FS.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("layer %q=%q: %v", traitType, value, err)
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("layer %q=%q: png.Decode(%q): %v", traitType, value, p, err)
+	}
+	if c.layers == nil {
+		c.layers = make(map[string]image.Image)
+	}
+	c.layers[p] = img
+	return img, nil
+}
+
+// ImageHandler returns an ImageHandler that renders the Composite() of the
+// Metadata returned by the MetadataHandler, encoded in c.Format. Non-200 codes
+// and errors from the MetadataHandler are propagated, and all other errors,
+// including missing layer files, result in a 500.
+func (c *LayerCompositor) ImageHandler(metadata MetadataHandler) ImageHandler {
+	return func(i Interface, id *TokenID, params httprouter.Params) (io.Reader, string, int, error) {
+		md, code, err := metadata(i, id, params)
+		if err != nil || code != 200 {
+			return nil, "", code, err
+		}
+		img, err := c.Composite(md)
+		if err != nil {
+			return nil, "", 500, fmt.Errorf("token %s: %v", id, err)
+		}
+
+		buf := new(bytes.Buffer)
+		if err := c.Format.Encode(buf, img); err != nil {
+			return nil, "", 500, fmt.Errorf("token %s: encode image: %v", id, err)
+		}
+		return buf, c.Format.ContentType(), 200, nil
+	}
+}
+
+// bmpHeaderSize is the combined size of the BITMAPFILEHEADER and
+// BITMAPINFOHEADER.
+const bmpHeaderSize = 54
+
+// EncodeBMP writes img to w as a 24-bit BMP, byte-for-byte identical to the
+// output of contracts/utils/BMP.sol's bmp() function with img's pixels, as BGR
+// tuples ordered bottom row first, as input. Alpha is ignored, after
+// premultiplication, so transparent pixels are black.
+func EncodeBMP(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+
+	stride := width * 3
+	padding := (4 - stride%4) % 4
+	paddedLength := height * (stride + padding)
+
+	buf := make([]byte, bmpHeaderSize, bmpHeaderSize+paddedLength)
+	le := binary.LittleEndian
+
+	// BITMAPFILEHEADER
+	buf[0x00], buf[0x01] = 'B', 'M'
+	le.PutUint32(buf[0x02:], uint32(bmpHeaderSize+paddedLength))
+	le.PutUint32(buf[0x0a:], bmpHeaderSize)
+
+	// BITMAPINFOHEADER; positive height indicates bottom-up rows. Remaining
+	// fields are zero, as in BMP.sol.
+	le.PutUint32(buf[0x0e:], 40)
+	le.PutUint32(buf[0x12:], uint32(width))
+	le.PutUint32(buf[0x16:], uint32(height))
+	le.PutUint16(buf[0x1a:], 1)
+	le.PutUint16(buf[0x1c:], 24)
+	le.PutUint32(buf[0x26:], 1)
+	le.PutUint32(buf[0x2a:], 1)
+
+	for y := b.Max.Y - 1; y >= b.Min.Y; y-- {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			buf = append(buf, c.B, c.G, c.R)
+		}
+		buf = append(buf, make([]byte, padding)...)
+	}
+
+	if _, err := w.Write(buf); err != nil {
+		return fmt.Errorf("write BMP: %v", err)
+	}
+	return nil
+}
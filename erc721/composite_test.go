@@ -0,0 +1,290 @@
+package erc721
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+	"github.com/julienschmidt/httprouter"
+	"golang.org/x/image/bmp"
+)
+
+var (
+	red         = color.RGBA{R: 0xff, A: 0xff}
+	green       = color.RGBA{G: 0xff, A: 0xff}
+	blue        = color.RGBA{B: 0xff, A: 0xff}
+	white       = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	transparent = color.RGBA{}
+)
+
+// rgbaImage returns an image with the rows of pixels.
+func rgbaImage(rows ...[]color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, len(rows[0]), len(rows)))
+	for y, row := range rows {
+		for x, c := range row {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// pngFile returns the PNG encoding of img as a MapFile.
+func pngFile(t *testing.T, img image.Image) *fstest.MapFile {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() error %v", err)
+	}
+	return &fstest.MapFile{Data: buf.Bytes()}
+}
+
+// pixels returns all pixels of img, row by row.
+func pixels(img image.Image) [][]color.RGBA {
+	b := img.Bounds()
+	var rows [][]color.RGBA
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		var row []color.RGBA
+		for x := b.Min.X; x < b.Max.X; x++ {
+			row = append(row, color.RGBAModel.Convert(img.At(x, y)).(color.RGBA))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func layerFS(t *testing.T) fstest.MapFS {
+	return fstest.MapFS{
+		"Background/Red.png":  pngFile(t, rgbaImage([]color.RGBA{red, red}, []color.RGBA{red, red})),
+		"Background/Blue.png": pngFile(t, rgbaImage([]color.RGBA{blue, blue}, []color.RGBA{blue, blue})),
+		"Hat/Top.png":         pngFile(t, rgbaImage([]color.RGBA{white, white}, []color.RGBA{transparent, transparent})),
+		"Eyes/Left.png":       pngFile(t, rgbaImage([]color.RGBA{transparent, transparent}, []color.RGBA{green, transparent})),
+		"Big/Layer.png":       pngFile(t, image.NewRGBA(image.Rect(0, 0, 3, 3))),
+		"Broken/Layer.png":    &fstest.MapFile{Data: []byte("not a png")},
+	}
+}
+
+func TestLayerCompositor(t *testing.T) {
+	c := &LayerCompositor{
+		FS:     layerFS(t),
+		Layers: []string{"Background", "Eyes", "Hat"},
+	}
+
+	tests := []struct {
+		name string
+		md   *Metadata
+		want [][]color.RGBA
+	}{
+		{
+			name: "all layers",
+			// Attribute order is irrelevant.
+			md: md(t, "Hat", "Top", "Background", "Red", "Eyes", "Left"),
+			want: [][]color.RGBA{
+				{white, white},
+				{green, red},
+			},
+		},
+		{
+			name: "missing trait and ignored trait",
+			md:   md(t, "Background", "Blue", "Hat", "Top", "Rarity Rank", "1"),
+			want: [][]color.RGBA{
+				{white, white},
+				{blue, blue},
+			},
+		},
+		{
+			name: "transparent background",
+			md:   md(t, "Eyes", "Left"),
+			want: [][]color.RGBA{
+				{transparent, transparent},
+				{green, transparent},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := c.Composite(tt.md)
+			if err != nil {
+				t.Fatalf("%T.Composite() error %v", c, err)
+			}
+			if diff := cmp.Diff(tt.want, pixels(img)); diff != "" {
+				t.Errorf("%T.Composite() pixels diff (-want +got):\n%s", c, diff)
+			}
+		})
+	}
+
+	t.Run("background colour", func(t *testing.T) {
+		c := &LayerCompositor{
+			FS:         layerFS(t),
+			Layers:     []string{"Eyes"},
+			Background: white,
+		}
+		img, err := c.Composite(md(t, "Eyes", "Left"))
+		if err != nil {
+			t.Fatalf("%T.Composite() error %v", c, err)
+		}
+		want := [][]color.RGBA{{white, white}, {green, white}}
+		if diff := cmp.Diff(want, pixels(img)); diff != "" {
+			t.Errorf("%T{Background: white}.Composite() pixels diff (-want +got):\n%s", c, diff)
+		}
+	})
+
+	t.Run("custom path", func(t *testing.T) {
+		c := &LayerCompositor{
+			FS:     fstest.MapFS{"layers/eyes-left.png": layerFS(t)["Eyes/Left.png"]},
+			Layers: []string{"Eyes"},
+			Path: func(traitType, value string) string {
+				return fmt.Sprintf("layers/%s-%s.png", strings.ToLower(traitType), strings.ToLower(value))
+			},
+		}
+		if _, err := c.Composite(md(t, "Eyes", "Left")); err != nil {
+			t.Errorf("%T{Path: custom}.Composite() error %v", c, err)
+		}
+	})
+}
+
+func TestLayerCompositorErrors(t *testing.T) {
+	c := &LayerCompositor{
+		FS:     layerFS(t),
+		Layers: []string{"Background", "Big", "Broken"},
+	}
+
+	tests := []struct {
+		name           string
+		md             *Metadata
+		errDiffAgainst interface{}
+	}{
+		{
+			name:           "missing file",
+			md:             md(t, "Background", "Green"),
+			errDiffAgainst: `layer "Background"="Green"`,
+		},
+		{
+			name:           "mismatched bounds",
+			md:             md(t, "Background", "Red", "Big", "Layer"),
+			errDiffAgainst: "bounds",
+		},
+		{
+			name:           "invalid PNG",
+			md:             md(t, "Broken", "Layer"),
+			errDiffAgainst: "png.Decode",
+		},
+		{
+			name:           "no layers",
+			md:             md(t, "Other", "x"),
+			errDiffAgainst: "no layers",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Composite(tt.md)
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Errorf("%T.Composite() %s", c, diff)
+			}
+		})
+	}
+}
+
+func TestEncodeBMP(t *testing.T) {
+	img := rgbaImage(
+		[]color.RGBA{white, red},
+		[]color.RGBA{green, blue},
+	)
+
+	var buf bytes.Buffer
+	if err := EncodeBMP(&buf, img); err != nil {
+		t.Fatalf("EncodeBMP() error %v", err)
+	}
+
+	// Equivalent to BMP.bmp(hex"00ff00_ff0000_ffffff_0000ff", 2, 2); i.e. BGR
+	// pixels with the bottom row first.
+	want := strings.Join([]string{
+		// BITMAPFILEHEADER
+		"424d", "46000000", "00000000", "36000000",
+		// BITMAPINFOHEADER
+		"28000000", "02000000", "02000000", "0100", "1800",
+		"00000000", "00000000", "01000000", "01000000", "00000000", "00000000",
+		// Pixels, with rows padded to 4 bytes
+		"00ff00", "ff0000", "0000",
+		"ffffff", "0000ff", "0000",
+	}, "")
+	if got := hex.EncodeToString(buf.Bytes()); got != want {
+		t.Errorf("EncodeBMP() got %s; want %s", got, want)
+	}
+
+	decoded, err := bmp.Decode(&buf)
+	if err != nil {
+		t.Fatalf("bmp.Decode(EncodeBMP()) error %v", err)
+	}
+	if diff := cmp.Diff(pixels(img), pixels(decoded)); diff != "" {
+		t.Errorf("bmp.Decode(EncodeBMP()) pixels diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestServerWithLayerCompositor(t *testing.T) {
+	coll := Collection{
+		*TokenIDFromInt(1): md(t, "Background", "Blue", "Hat", "Top"),
+		*TokenIDFromInt(2): md(t, "Background", "Missing"),
+	}
+	metadata := func(_ Interface, id *TokenID, _ httprouter.Params) (*Metadata, int, error) {
+		md, ok := coll[*id]
+		if !ok {
+			return nil, 404, nil
+		}
+		return md, 200, nil
+	}
+
+	for _, format := range []ImageFormat{PNGFormat, BMPFormat} {
+		t.Run(format.ContentType(), func(t *testing.T) {
+			c := &LayerCompositor{
+				FS:     layerFS(t),
+				Layers: []string{"Background", "Hat"},
+				Format: format,
+			}
+			srv := &Server{
+				Metadata: []MetadataEndpoint{{
+					Path:    "/metadata/:tokenId",
+					Handler: metadata,
+				}},
+				Image: []ImageEndpoint{{
+					Path:    "/image/:tokenId",
+					Handler: c.ImageHandler(metadata),
+				}},
+			}
+			baseURL := start(t, srv)
+
+			resp := httpGet(t, baseURL+"/image/1")
+			defer resp.Body.Close()
+			testContentType(t, resp, format.ContentType())
+
+			img, _, err := image.Decode(resp.Body)
+			if err != nil {
+				t.Fatalf("image.Decode([%s response]) error %v", format.ContentType(), err)
+			}
+			want := [][]color.RGBA{{white, white}, {blue, blue}}
+			if diff := cmp.Diff(want, pixels(img)); diff != "" {
+				t.Errorf("Composited image pixels diff (-want +got):\n%s", diff)
+			}
+
+			for path, code := range map[string]int{
+				"/image/2": 500,
+				"/image/3": 404,
+			} {
+				resp := httpGet(t, baseURL+path)
+				resp.Body.Close()
+				if got := resp.StatusCode; got != code {
+					t.Errorf("HTTP GET %q got code %d; want %d", path, got, code)
+				}
+			}
+		})
+	}
+}
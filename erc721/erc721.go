@@ -2,9 +2,15 @@
 package erc721
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // A Collection is a set of Metadata, each associated with a single token ID.
@@ -30,6 +36,42 @@ type Metadata struct {
 	ExternalURL    string       `json:"external_url,omitempty"`
 	CollectionName string       `json:"collection_name,omitempty"`
 	Attributes     []*Attribute `json:"attributes,omitempty"`
+	// BackgroundColor is a six-character hexadecimal colour, without a
+	// leading #.
+	BackgroundColor string `json:"background_color,omitempty"`
+	YouTubeURL      string `json:"youtube_url,omitempty"`
+	// ImageData is raw image data, typically an inline SVG, used by OpenSea
+	// in place of Image for on-chain art.
+	ImageData  string                 `json:"image_data,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+
+	// Extra carries all JSON fields not otherwise modelled by Metadata. They
+	// are populated by json.Unmarshal() and included by json.Marshal(), thus
+	// preserving unknown fields on round-trip. Fields modelled by Metadata
+	// take precedence over those with the same name in Extra.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// metadataFields has the same fields as Metadata, but without its JSON
+// methods.
+type metadataFields Metadata
+
+// MarshalJSON marshals all fields of the Metadata, including Extra.
+func (md Metadata) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(metadataFields(md), md.Extra)
+}
+
+// UnmarshalJSON unmarshals the buffer into the Metadata, storing unknown fields
+// in Extra.
+func (md *Metadata) UnmarshalJSON(buf []byte) error {
+	var f metadataFields
+	extra, err := unmarshalWithExtra(buf, &f)
+	if err != nil {
+		return err
+	}
+	*md = Metadata(f)
+	md.Extra = extra
+	return nil
 }
 
 // MarshalJSONTo marshals the Metadata to JSON and writes it to the Writer,
@@ -42,11 +84,49 @@ func (md *Metadata) MarshalJSONTo(w io.Writer) (int, error) {
 	return w.Write(buf)
 }
 
+// ContractMetadata carries collection-level metadata, as returned by a
+// contract's contractURI function and compatible with OpenSea.
+type ContractMetadata struct {
+	Name                 string          `json:"name,omitempty"`
+	Description          string          `json:"description,omitempty"`
+	Image                string          `json:"image,omitempty"`
+	ExternalLink         string          `json:"external_link,omitempty"`
+	SellerFeeBasisPoints uint16          `json:"seller_fee_basis_points,omitempty"`
+	FeeRecipient         *common.Address `json:"fee_recipient,omitempty"`
+}
+
 // An Attribute is a single attribute in Metadata.
 type Attribute struct {
 	TraitType   string             `json:"trait_type,omitempty"`
 	Value       interface{}        `json:"value"`
 	DisplayType OpenSeaDisplayType `json:"display_type,omitempty"`
+	// MaxValue, if non-nil, is the upper bound of a numerical Value.
+	MaxValue interface{} `json:"max_value,omitempty"`
+
+	// Extra carries unknown JSON fields, as for Metadata.Extra.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// attributeFields has the same fields as Attribute, but without its JSON
+// methods.
+type attributeFields Attribute
+
+// MarshalJSON marshals all fields of the Attribute, including Extra.
+func (a Attribute) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(attributeFields(a), a.Extra)
+}
+
+// UnmarshalJSON unmarshals the buffer into the Attribute, storing unknown
+// fields in Extra.
+func (a *Attribute) UnmarshalJSON(buf []byte) error {
+	var f attributeFields
+	extra, err := unmarshalWithExtra(buf, &f)
+	if err != nil {
+		return err
+	}
+	*a = Attribute(f)
+	a.Extra = extra
+	return nil
 }
 
 // An OpenSeaDisplayType is an OpenSea-specific metadata concept to control how
@@ -122,3 +202,84 @@ func (t *OpenSeaDisplayType) UnmarshalJSON(buf []byte) error {
 func (a *Attribute) String() string {
 	return fmt.Sprintf("%s:%v", a.TraitType, a.Value)
 }
+
+// marshalWithExtra returns the JSON encoding of v, which MUST marshal to a JSON
+// object, with the extra fields appended in lexicographical order. Extra
+// fields are ignored if v already has a field of the same name.
+func marshalWithExtra(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	buf, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return buf, err
+	}
+
+	var known map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &known); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(%T as JSON): %v", v, err)
+	}
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		if _, ok := known[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	buf = bytes.TrimSuffix(buf, []byte("}"))
+	for i, k := range keys {
+		if i > 0 || len(known) > 0 {
+			buf = append(buf, ',')
+		}
+		kv, err := json.Marshal(map[string]json.RawMessage{k: extra[k]})
+		if err != nil {
+			return nil, fmt.Errorf("extra JSON field %q: %v", k, err)
+		}
+		buf = append(buf, kv[1:len(kv)-1]...)
+	}
+	return append(buf, '}'), nil
+}
+
+// unmarshalWithExtra unmarshals buf into v, which MUST be a pointer to a
+// struct, returning all fields of the JSON object that aren't fields of the
+// struct. The returned map is nil if there are no such fields.
+func unmarshalWithExtra(buf []byte, v interface{}) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(buf, v); err != nil {
+		return nil, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &all); err != nil {
+		return nil, err
+	}
+	known := jsonFieldNames(reflect.TypeOf(v).Elem())
+	for k := range all {
+		// encoding/json matches field names case-insensitively.
+		if known[strings.ToLower(k)] {
+			delete(all, k)
+		}
+	}
+	if len(all) == 0 {
+		return nil, nil
+	}
+	return all, nil
+}
+
+// jsonFieldNames returns the lower-cased JSON names of all exported fields of
+// the struct type, excluding those tagged with "-".
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+		names[strings.ToLower(name)] = true
+	}
+	return names
+}
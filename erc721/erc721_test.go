@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -67,3 +68,122 @@ func TestOpenSeaDisplayType(t *testing.T) {
 		})
 	}
 }
+
+func TestContractMetadataJSON(t *testing.T) {
+	recipient := common.HexToAddress("0x71e059FA4594b69200541A189010188eDFFbC34D")
+	md := &ContractMetadata{
+		Name:                 "Collection",
+		Description:          "Things",
+		Image:                "ipfs://foo",
+		ExternalLink:         "https://example.com",
+		SellerFeeBasisPoints: 250,
+		FeeRecipient:         &recipient,
+	}
+
+	buf, err := json.Marshal(md)
+	if err != nil {
+		t.Fatalf("json.Marshal(%T) error %v", md, err)
+	}
+	want := `{"name":"Collection","description":"Things","image":"ipfs://foo","external_link":"https://example.com","seller_fee_basis_points":250,"fee_recipient":"0x71e059fa4594b69200541a189010188edffbc34d"}`
+	if string(buf) != want {
+		t.Errorf("json.Marshal(%+v) got %s; want %s", md, buf, want)
+	}
+}
+
+func TestMetadataJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		asJSON string
+		want   *Metadata
+	}{
+		{
+			name:   "known fields only",
+			asJSON: `{"name":"Token","image_data":"<svg/>","attributes":[{"trait_type":"Level","value":3,"display_type":"number","max_value":10}],"background_color":"ff00aa","youtube_url":"https://youtu.be/x","properties":{"edition":1}}`,
+			want: &Metadata{
+				Name:      "Token",
+				ImageData: "<svg/>",
+				Attributes: []*Attribute{{
+					TraitType:   "Level",
+					Value:       float64(3),
+					DisplayType: DisplayNumber,
+					MaxValue:    float64(10),
+				}},
+				BackgroundColor: "ff00aa",
+				YouTubeURL:      "https://youtu.be/x",
+				Properties:      map[string]interface{}{"edition": float64(1)},
+			},
+		},
+		{
+			name:   "unknown fields",
+			asJSON: `{"name":"Token","attributes":[{"value":"x","rarity":"common"}],"dna":"abc123","z":{"nested":[1,2]}}`,
+			want: &Metadata{
+				Name: "Token",
+				Attributes: []*Attribute{{
+					Value: "x",
+					Extra: map[string]json.RawMessage{
+						"rarity": json.RawMessage(`"common"`),
+					},
+				}},
+				Extra: map[string]json.RawMessage{
+					"dna": json.RawMessage(`"abc123"`),
+					"z":   json.RawMessage(`{"nested":[1,2]}`),
+				},
+			},
+		},
+		{
+			name:   "only unknown fields",
+			asJSON: `{"a":true}`,
+			want: &Metadata{
+				Extra: map[string]json.RawMessage{
+					"a": json.RawMessage(`true`),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := new(Metadata)
+			if err := json.Unmarshal([]byte(tt.asJSON), got); err != nil {
+				t.Fatalf("json.Unmarshal(%s) error %v", tt.asJSON, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("json.Unmarshal(%s) diff (-want +got):\n%s", tt.asJSON, diff)
+			}
+
+			buf, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("json.Marshal(%+v) error %v", got, err)
+			}
+			// Field order and escaping may differ, so compare generic
+			// representations.
+			var want, roundTrip interface{}
+			if err := json.Unmarshal([]byte(tt.asJSON), &want); err != nil {
+				t.Fatalf("json.Unmarshal(%s, %T) error %v", tt.asJSON, &want, err)
+			}
+			if err := json.Unmarshal(buf, &roundTrip); err != nil {
+				t.Fatalf("json.Unmarshal(%s, %T) error %v", buf, &roundTrip, err)
+			}
+			if diff := cmp.Diff(want, roundTrip); diff != "" {
+				t.Errorf("json.Marshal(json.Unmarshal(%s)) diff (-want +got):\n%s", tt.asJSON, diff)
+			}
+		})
+	}
+}
+
+func TestMetadataExtraPrecedence(t *testing.T) {
+	md := Metadata{
+		Name: "known",
+		Extra: map[string]json.RawMessage{
+			"name":  json.RawMessage(`"extra"`),
+			"other": json.RawMessage(`1`),
+		},
+	}
+	got, err := json.Marshal(md)
+	if err != nil {
+		t.Fatalf("json.Marshal(%+v) error %v", md, err)
+	}
+	if want := `{"name":"known","other":1}`; string(got) != want {
+		t.Errorf("json.Marshal(%+v) got %s; want %s", md, got, want)
+	}
+}
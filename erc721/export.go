@@ -0,0 +1,286 @@
+package erc721
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/divergencetech/ethier/ipfs"
+	"github.com/julienschmidt/httprouter"
+)
+
+// ExportConfig configures Server.Export().
+type ExportConfig struct {
+	// Dir is the directory to which files are written. It is created if it
+	// doesn't already exist.
+	Dir string
+	// From and To define the inclusive range of tokens to export.
+	From, To *TokenID
+	// ImageBaseURI, if non-empty, is the prefix of the image URL of every
+	// exported token's metadata; e.g. "ar://<id>/". If empty, it defaults to
+	// "ipfs://<CID of the images directory>/". The URL of each image is the
+	// base followed by the image's file name.
+	ImageBaseURI string
+}
+
+// Export directory names, relative to ExportConfig.Dir.
+const (
+	ExportMetadataDir = "metadata"
+	ExportImagesDir   = "images"
+)
+
+// An Export describes the files written by Server.Export(). CIDs are computed
+// locally, as described in the ipfs package, and will match those of the
+// directories when uploaded to IPFS with `ipfs add --cid-version=1`. Directories
+// that IPFS would shard, which have a different CID, aren't supported so
+// Export() returns an error for them; see ipfs.HAMTShardingSize.
+type Export struct {
+	// Root is the CID of the export directory, containing the metadata and
+	// images directories.
+	Root ipfs.CID
+	// Metadata is the CID of the metadata directory; i.e. the contract's base
+	// tokenURI is "ipfs://<Metadata>/".
+	Metadata ipfs.CID
+	// Images is the CID of the images directory, and is only valid if the
+	// Server has an ImageEndpoint.
+	Images ipfs.CID
+	// Files maps the slash-separated path of each exported file, relative to
+	// ExportConfig.Dir, to its CID; e.g. "metadata/42" and "images/42.png".
+	Files map[string]ipfs.CID
+}
+
+// Export writes static copies of the metadata and images of every token in
+// the configured range to the directory tree:
+//
+//	<Dir>/metadata/<id>
+//	<Dir>/images/<id>.<ext>
+//
+// Token IDs are formatted in the Server's TokenIDBase, and the file extension
+// of each image is derived from the content type returned by the ImageHandler.
+// Data are sourced from the first MetadataEndpoint and, if present, the first
+// ImageEndpoint, with handlers receiving only the TokenIDParam in their
+// Params. The Image field of each token's Metadata is replaced with the
+// image's URL under cfg.ImageBaseURI; it is left untouched if the Server has
+// no ImageEndpoint.
+//
+// Exporting is intended for freezing metadata after a mint is complete, so all
+// tokens MUST exist, as determined by the Server's Mints or Contract, and all
+// handlers MUST return code 200. If s.RejectInvalidMetadata is true, all
+// Metadata MUST also be valid. Export doesn't require a call to s.Handler().
+//
+// The metadata and images directories MUST NOT be large enough to be sharded
+// by IPFS, which typically limits an export to around 6000 tokens; see the
+// Export type. Files are, however, written before this is detected.
+func (s *Server) Export(cfg ExportConfig) (*Export, error) {
+	if len(s.Metadata) == 0 {
+		return nil, fmt.Errorf("%T.Export() requires at least one MetadataEndpoint", s)
+	}
+	if cfg.From == nil || cfg.To == nil {
+		return nil, fmt.Errorf("%T.Export() requires ExportConfig.From and .To", s)
+	}
+	if cfg.From.Cmp(cfg.To) > 0 {
+		return nil, fmt.Errorf("ExportConfig.From (%s) > .To (%s)", cfg.From, cfg.To)
+	}
+
+	var ids []*TokenID
+	for i, to := cfg.From.Big(), cfg.To.Big(); i.Cmp(to) <= 0; i.Add(i, big.NewInt(1)) {
+		id, err := TokenIDFromBig(i)
+		if err != nil {
+			return nil, err
+		}
+		if !s.minted(id) {
+			return nil, fmt.Errorf("token %s not minted", id)
+		}
+		ids = append(ids, id)
+	}
+
+	exp := &Export{
+		Files: make(map[string]ipfs.CID),
+	}
+	e := exporter{
+		Server: s,
+		dir:    cfg.Dir,
+		export: exp,
+	}
+
+	dirs := make(map[string]ipfs.DAG)
+
+	imageFiles := make(map[TokenID]string)
+	if len(s.Image) > 0 {
+		images := make(map[string]ipfs.DAG)
+		for _, id := range ids {
+			name, dag, err := e.image(id)
+			if err != nil {
+				return nil, err
+			}
+			images[name] = dag
+			imageFiles[*id] = name
+		}
+
+		dag, err := e.directory(ExportImagesDir, images)
+		if err != nil {
+			return nil, err
+		}
+		exp.Images = dag.CID
+		dirs[ExportImagesDir] = dag
+	}
+
+	base := cfg.ImageBaseURI
+	if base == "" {
+		base = exp.Images.URL() + "/"
+	}
+
+	metadata := make(map[string]ipfs.DAG)
+	for _, id := range ids {
+		md, err := e.metadata(id)
+		if err != nil {
+			return nil, err
+		}
+		if name, ok := imageFiles[*id]; ok {
+			md.Image = base + name
+		}
+		if err := s.validate(md); err != nil {
+			return nil, fmt.Errorf("token %s: %v", id, err)
+		}
+
+		buf, err := json.Marshal(md)
+		if err != nil {
+			return nil, fmt.Errorf("json.Marshal(%T = %+v): %v", md, md, err)
+		}
+		name := s.tokenIDText(id)
+		dag, err := e.writeFile(ExportMetadataDir, name, buf)
+		if err != nil {
+			return nil, err
+		}
+		metadata[name] = dag
+	}
+
+	dag, err := e.directory(ExportMetadataDir, metadata)
+	if err != nil {
+		return nil, err
+	}
+	exp.Metadata = dag.CID
+	dirs[ExportMetadataDir] = dag
+
+	root, err := ipfs.Directory(dirs)
+	if err != nil {
+		return nil, err
+	}
+	exp.Root = root.CID
+
+	return exp, nil
+}
+
+// An exporter writes the files of a single call to Server.Export().
+type exporter struct {
+	*Server
+	dir    string
+	export *Export
+}
+
+// params returns the Params passed to handlers during an export.
+func (e *exporter) params(id *TokenID) httprouter.Params {
+	return httprouter.Params{{
+		Key:   TokenIDParam,
+		Value: e.tokenIDText(id),
+	}}
+}
+
+// metadata returns the Metadata of the token from the first MetadataEndpoint.
+func (e *exporter) metadata(id *TokenID) (*Metadata, error) {
+	md, code, err := e.Metadata[0].Handler(e.Contract, id, e.params(id))
+	if err != nil {
+		return nil, fmt.Errorf("Metadata(%s): %v", id, err)
+	}
+	if code != 200 {
+		return nil, fmt.Errorf("Metadata(%s) returned code %d", id, code)
+	}
+	return md, nil
+}
+
+// image writes the token's image from the first ImageEndpoint, returning its
+// file name.
+func (e *exporter) image(id *TokenID) (string, ipfs.DAG, error) {
+	img, contentType, code, err := e.Image[0].Handler(e.Contract, id, e.params(id))
+	if err != nil {
+		return "", ipfs.DAG{}, fmt.Errorf("Image(%s): %v", id, err)
+	}
+	if code != 200 {
+		return "", ipfs.DAG{}, fmt.Errorf("Image(%s) returned code %d", id, code)
+	}
+
+	ext, err := imageExtension(contentType)
+	if err != nil {
+		return "", ipfs.DAG{}, fmt.Errorf("Image(%s): %v", id, err)
+	}
+	buf, err := io.ReadAll(img)
+	if err != nil {
+		return "", ipfs.DAG{}, fmt.Errorf("io.ReadAll([Image(%s) data]): %v", id, err)
+	}
+
+	name := e.tokenIDText(id) + ext
+	dag, err := e.writeFile(ExportImagesDir, name, buf)
+	if err != nil {
+		return "", ipfs.DAG{}, err
+	}
+	return name, dag, nil
+}
+
+// writeFile writes the data to <e.dir>/<subdir>/<name>, recording its CID.
+func (e *exporter) writeFile(subdir, name string, data []byte) (ipfs.DAG, error) {
+	dir := filepath.Join(e.dir, subdir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ipfs.DAG{}, fmt.Errorf("os.MkdirAll(%q): %v", dir, err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return ipfs.DAG{}, fmt.Errorf("os.WriteFile(%q): %v", path, err)
+	}
+
+	dag := ipfs.File(data)
+	e.export.Files[subdir+"/"+name] = dag.CID
+	return dag, nil
+}
+
+// directory returns ipfs.Directory(entries), with a contextual error.
+func (e *exporter) directory(name string, entries map[string]ipfs.DAG) (ipfs.DAG, error) {
+	dag, err := ipfs.Directory(entries)
+	if err != nil {
+		return ipfs.DAG{}, fmt.Errorf("%s directory: %v", name, err)
+	}
+	return dag, nil
+}
+
+// imageExtensions maps common image content types to file extensions. Unlike
+// mime.ExtensionsByType(), it is independent of the host system.
+var imageExtensions = map[string]string{
+	"image/bmp":     ".bmp",
+	"image/gif":     ".gif",
+	"image/jpeg":    ".jpg",
+	"image/png":     ".png",
+	"image/svg+xml": ".svg",
+	"image/webp":    ".webp",
+}
+
+// imageExtension returns the file extension, including the leading dot, for
+// the content type.
+func imageExtension(contentType string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("mime.ParseMediaType(%q): %v", contentType, err)
+	}
+	if ext, ok := imageExtensions[strings.ToLower(mediaType)]; ok {
+		return ext, nil
+	}
+
+	exts, err := mime.ExtensionsByType(mediaType)
+	if err != nil || len(exts) == 0 {
+		return "", fmt.Errorf("no file extension for content type %q", contentType)
+	}
+	return exts[0], nil
+}
@@ -0,0 +1,289 @@
+package erc721
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/divergencetech/ethier/ipfs"
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+	"github.com/julienschmidt/httprouter"
+)
+
+// exportServer returns a Server with metadata and image endpoints suitable for
+// testing Server.Export().
+func exportServer(contract Interface) *Server {
+	return &Server{
+		Contract: contract,
+		Metadata: []MetadataEndpoint{{
+			Path: "/metadata/:tokenId",
+			Handler: func(_ Interface, id *TokenID, params httprouter.Params) (*Metadata, int, error) {
+				return &Metadata{
+					Name:  fmt.Sprintf("Token %s", params.ByName(TokenIDParam)),
+					Image: "https://example.com/will-be-replaced",
+				}, 200, nil
+			},
+		}},
+		Image: []ImageEndpoint{{
+			Path: "/image/:tokenId",
+			Handler: func(_ Interface, id *TokenID, _ httprouter.Params) (io.Reader, string, int, error) {
+				return bytes.NewReader([]byte("image " + id.String())), "image/png", 200, nil
+			},
+		}},
+	}
+}
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		name         string
+		tokenIDBase  int
+		imageBaseURI string
+		// wantImage returns the expected Metadata.Image for the token, given
+		// the Export.
+		wantImage func(*Export, string) string
+		wantFiles []string
+	}{
+		{
+			name: "default IPFS image base",
+			wantImage: func(e *Export, file string) string {
+				return fmt.Sprintf("ipfs://%s/%s", e.Images, file)
+			},
+			wantFiles: []string{"8", "9", "10"},
+		},
+		{
+			name:         "custom image base",
+			imageBaseURI: "ar://abc/",
+			wantImage: func(_ *Export, file string) string {
+				return "ar://abc/" + file
+			},
+			wantFiles: []string{"8", "9", "10"},
+		},
+		{
+			name:        "hex token IDs",
+			tokenIDBase: 16,
+			wantImage: func(e *Export, file string) string {
+				return fmt.Sprintf("ipfs://%s/%s", e.Images, file)
+			},
+			wantFiles: []string{"8", "9", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := exportServer(&fakeContract{totalSupply: 11})
+			srv.TokenIDBase = tt.tokenIDBase
+
+			dir := t.TempDir()
+			got, err := srv.Export(ExportConfig{
+				Dir:          dir,
+				From:         TokenIDFromInt(8),
+				To:           TokenIDFromInt(10),
+				ImageBaseURI: tt.imageBaseURI,
+			})
+			if err != nil {
+				t.Fatalf("%T.Export() error %v", srv, err)
+			}
+
+			read := func(t *testing.T, path ...string) []byte {
+				t.Helper()
+				buf, err := os.ReadFile(filepath.Join(append([]string{dir}, path...)...))
+				if err != nil {
+					t.Fatalf("os.ReadFile() error %v", err)
+				}
+				return buf
+			}
+
+			metadata := make(map[string]ipfs.DAG)
+			images := make(map[string]ipfs.DAG)
+			wantCIDs := make(map[string]string)
+
+			for _, name := range tt.wantFiles {
+				mdBuf := read(t, ExportMetadataDir, name)
+				metadata[name] = ipfs.File(mdBuf)
+				wantCIDs[ExportMetadataDir+"/"+name] = ipfs.File(mdBuf).CID.String()
+
+				imgFile := name + ".png"
+				imgBuf := read(t, ExportImagesDir, imgFile)
+				images[imgFile] = ipfs.File(imgBuf)
+				wantCIDs[ExportImagesDir+"/"+imgFile] = ipfs.File(imgBuf).CID.String()
+
+				var md Metadata
+				if err := json.Unmarshal(mdBuf, &md); err != nil {
+					t.Fatalf("json.Unmarshal(%s/%s) error %v", ExportMetadataDir, name, err)
+				}
+				want := Metadata{
+					Name:  "Token " + name,
+					Image: tt.wantImage(got, imgFile),
+				}
+				if diff := cmp.Diff(want, md); diff != "" {
+					t.Errorf("Exported metadata %q diff (-want +got):\n%s", name, diff)
+				}
+			}
+
+			gotCIDs := make(map[string]string)
+			for path, c := range got.Files {
+				gotCIDs[path] = c.String()
+			}
+			if diff := cmp.Diff(wantCIDs, gotCIDs); diff != "" {
+				t.Errorf("%T.Files diff (-want +got):\n%s", got, diff)
+			}
+
+			mdDir, err := ipfs.Directory(metadata)
+			if err != nil {
+				t.Fatalf("ipfs.Directory(metadata) error %v", err)
+			}
+			imgDir, err := ipfs.Directory(images)
+			if err != nil {
+				t.Fatalf("ipfs.Directory(images) error %v", err)
+			}
+			root, err := ipfs.Directory(map[string]ipfs.DAG{
+				ExportMetadataDir: mdDir,
+				ExportImagesDir:   imgDir,
+			})
+			if err != nil {
+				t.Fatalf("ipfs.Directory(root) error %v", err)
+			}
+
+			for _, c := range []struct {
+				name      string
+				got, want ipfs.CID
+			}{
+				{"Metadata", got.Metadata, mdDir.CID},
+				{"Images", got.Images, imgDir.CID},
+				{"Root", got.Root, root.CID},
+			} {
+				if c.got != c.want {
+					t.Errorf("%T.%s = %s; want %s", got, c.name, c.got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestExportWithoutImages(t *testing.T) {
+	srv := exportServer(nil)
+	srv.Image = nil
+
+	dir := t.TempDir()
+	if _, err := srv.Export(ExportConfig{
+		Dir:  dir,
+		From: TokenIDFromInt(0),
+		To:   TokenIDFromInt(0),
+	}); err != nil {
+		t.Fatalf("%T.Export() error %v", srv, err)
+	}
+
+	buf, err := os.ReadFile(filepath.Join(dir, ExportMetadataDir, "0"))
+	if err != nil {
+		t.Fatalf("os.ReadFile() error %v", err)
+	}
+	var md Metadata
+	if err := json.Unmarshal(buf, &md); err != nil {
+		t.Fatalf("json.Unmarshal() error %v", err)
+	}
+	if got, want := md.Image, "https://example.com/will-be-replaced"; got != want {
+		t.Errorf("Exported metadata without ImageEndpoint; got Image %q; want unchanged %q", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ExportImagesDir)); !os.IsNotExist(err) {
+		t.Errorf("os.Stat(%s) got err %v; want does-not-exist", ExportImagesDir, err)
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		modify         func(*Server)
+		from, to       int
+		errDiffAgainst interface{}
+	}{
+		{
+			name:           "not minted",
+			from:           0,
+			to:             5,
+			errDiffAgainst: "token 5 not minted",
+		},
+		{
+			name:           "inverted range",
+			from:           2,
+			to:             1,
+			errDiffAgainst: "> .To",
+		},
+		{
+			name: "no metadata endpoint",
+			modify: func(s *Server) {
+				s.Metadata = nil
+			},
+			errDiffAgainst: "MetadataEndpoint",
+		},
+		{
+			name: "non-200 metadata",
+			modify: func(s *Server) {
+				s.Metadata[0].Handler = func(Interface, *TokenID, httprouter.Params) (*Metadata, int, error) {
+					return nil, 404, nil
+				}
+			},
+			errDiffAgainst: "returned code 404",
+		},
+		{
+			name: "unknown image type",
+			modify: func(s *Server) {
+				s.Image[0].Handler = func(Interface, *TokenID, httprouter.Params) (io.Reader, string, int, error) {
+					return bytes.NewReader(nil), "application/x-nothing-known", 200, nil
+				}
+			},
+			errDiffAgainst: "no file extension",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := exportServer(&fakeContract{totalSupply: 5})
+			if tt.modify != nil {
+				tt.modify(srv)
+			}
+			_, err := srv.Export(ExportConfig{
+				Dir:  t.TempDir(),
+				From: TokenIDFromInt(tt.from),
+				To:   TokenIDFromInt(tt.to),
+			})
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Errorf("%T.Export() %s", srv, diff)
+			}
+		})
+	}
+}
+
+func TestImageExtension(t *testing.T) {
+	for contentType, want := range map[string]string{
+		"image/png":                ".png",
+		"image/jpeg":               ".jpg",
+		"image/svg+xml":            ".svg",
+		"image/svg+xml; charset=x": ".svg",
+		"IMAGE/BMP":                ".bmp",
+	} {
+		got, err := imageExtension(contentType)
+		if err != nil || got != want {
+			t.Errorf("imageExtension(%q) got (%q, %v); want (%q, nil)", contentType, got, err, want)
+		}
+	}
+}
+
+func TestExportShardedDirectory(t *testing.T) {
+	const n = 7000
+	srv := exportServer(&fakeContract{totalSupply: n})
+	srv.Image = nil
+
+	_, err := srv.Export(ExportConfig{
+		Dir:  t.TempDir(),
+		From: TokenIDFromInt(0),
+		To:   TokenIDFromInt(n - 1),
+	})
+	if diff := errdiff.Substring(err, "metadata directory: directory of 7000 entries would be sharded"); diff != "" {
+		t.Errorf("%T.Export(%d tokens) %s", srv, n, diff)
+	}
+}
@@ -0,0 +1,325 @@
+package erc721
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"strings"
+)
+
+// A GenerationSpec declaratively describes a Collection of tokens with
+// randomly selected, weighted traits. See GenerationSpec.Generate().
+type GenerationSpec struct {
+	// MaxSupply is the number of tokens to generate, each with a unique
+	// combination of traits.
+	MaxSupply int `json:"max_supply"`
+	// FirstTokenID is the ID of the first token; subsequent tokens are
+	// numbered sequentially.
+	FirstTokenID uint64 `json:"first_token_id,omitempty"`
+	// Traits are selected in the order in which they are specified.
+	Traits []*TraitSpec `json:"traits"`
+	// Incompatible lists sets of trait values of which a token may carry at
+	// most one.
+	Incompatible [][]TraitValue `json:"incompatible,omitempty"`
+	// Dependencies restrict the values of a trait based on those of another.
+	Dependencies []*TraitDependency `json:"dependencies,omitempty"`
+	// MaxAttempts is the maximum number of attempts to generate each token
+	// before Generate() fails; an attempt fails if it results in a duplicate
+	// combination of traits or if no value of a trait can satisfy all rules.
+	// Defaults to DefaultMaxGenerationAttempts if zero.
+	MaxAttempts int `json:"max_attempts,omitempty"`
+}
+
+// DefaultMaxGenerationAttempts is the default value of
+// GenerationSpec.MaxAttempts.
+const DefaultMaxGenerationAttempts = 1000
+
+// A TraitSpec describes the possible values of a single TraitType.
+type TraitSpec struct {
+	TraitType string       `json:"trait_type"`
+	Values    []*ValueSpec `json:"values"`
+}
+
+// A ValueSpec is a possible value of a trait. The probability of a value being
+// selected, in the absence of rules, is its Weight divided by the sum of the
+// Weights of all of the trait's values. An empty Value results in tokens
+// lacking the TraitType altogether.
+type ValueSpec struct {
+	Value  string  `json:"value"`
+	Weight float64 `json:"weight"`
+}
+
+// A TraitValue identifies a single value of a TraitType. An empty Value refers
+// to the lack of the TraitType.
+type TraitValue struct {
+	TraitType string `json:"trait_type"`
+	Value     string `json:"value"`
+}
+
+// A TraitDependency requires that all tokens with the If trait value also have
+// one of the Values of the Then TraitType.
+type TraitDependency struct {
+	If     TraitValue `json:"if"`
+	Then   string     `json:"then"`
+	Values []string   `json:"values"`
+}
+
+// Validate returns an error if the GenerationSpec is malformed or if it can't
+// possibly result in MaxSupply unique tokens, ignoring rules.
+func (s *GenerationSpec) Validate() error {
+	if s.MaxSupply < 1 {
+		return fmt.Errorf("max_supply must be positive; got %d", s.MaxSupply)
+	}
+	if len(s.Traits) == 0 {
+		return fmt.Errorf("no traits")
+	}
+
+	values := make(map[TraitValue]bool)
+	combinations := big.NewInt(1)
+	for i, t := range s.Traits {
+		if t == nil {
+			return fmt.Errorf("traits[%d]: nil", i)
+		}
+		if t.TraitType == "" {
+			return fmt.Errorf("traits[%d]: empty trait_type", i)
+		}
+		if values[TraitValue{TraitType: t.TraitType}] {
+			return fmt.Errorf("trait %q: duplicate trait_type", t.TraitType)
+		}
+		// The lack of the TraitType is always a valid value for rules to
+		// refer to, even if it is never selected.
+		values[TraitValue{TraitType: t.TraitType}] = true
+
+		var (
+			total    float64
+			possible int64
+		)
+		seen := make(map[string]bool)
+		for _, v := range t.Values {
+			if v == nil {
+				return fmt.Errorf("trait %q: nil value", t.TraitType)
+			}
+			if seen[v.Value] {
+				return fmt.Errorf("trait %q: duplicate value %q", t.TraitType, v.Value)
+			}
+			seen[v.Value] = true
+			if v.Weight < 0 {
+				return fmt.Errorf("trait %q value %q: negative weight %v", t.TraitType, v.Value, v.Weight)
+			}
+			total += v.Weight
+			if v.Weight > 0 {
+				possible++
+			}
+			values[TraitValue{t.TraitType, v.Value}] = true
+		}
+		if !(total > 0) {
+			return fmt.Errorf("trait %q: total weight must be positive", t.TraitType)
+		}
+		combinations.Mul(combinations, big.NewInt(possible))
+	}
+	if combinations.Cmp(big.NewInt(int64(s.MaxSupply))) < 0 {
+		return fmt.Errorf("max_supply %d exceeds number of possible combinations %s", s.MaxSupply, combinations)
+	}
+
+	for i, inc := range s.Incompatible {
+		if len(inc) < 2 {
+			return fmt.Errorf("incompatible[%d]: must have at least 2 values", i)
+		}
+		for _, v := range inc {
+			if !values[v] {
+				return fmt.Errorf("incompatible[%d]: unknown trait value %q=%q", i, v.TraitType, v.Value)
+			}
+		}
+	}
+	for i, d := range s.Dependencies {
+		if d == nil {
+			return fmt.Errorf("dependencies[%d]: nil", i)
+		}
+		if !values[d.If] {
+			return fmt.Errorf("dependencies[%d]: unknown trait value %q=%q", i, d.If.TraitType, d.If.Value)
+		}
+		if d.Then == d.If.TraitType {
+			return fmt.Errorf("dependencies[%d]: trait %q depends on itself", i, d.Then)
+		}
+		if len(d.Values) == 0 {
+			return fmt.Errorf("dependencies[%d]: no values", i)
+		}
+		for _, v := range d.Values {
+			if !values[TraitValue{d.Then, v}] {
+				return fmt.Errorf("dependencies[%d]: unknown trait value %q=%q", i, d.Then, v)
+			}
+		}
+	}
+	return nil
+}
+
+// Generate returns a Collection of s.MaxSupply tokens, each with a unique
+// combination of traits that satisfies all rules. The Collection is fully
+// determined by the GenerationSpec and the sequence of values returned by rng.
+//
+// Each token's traits are selected in the order of s.Traits. The values of
+// each trait are restricted to those that are consistent with all rules, given
+// the previously selected traits, and one is then selected with probability
+// proportional to the Weights of the remaining values. An attempt is
+// abandoned, and the token regenerated, if no value is consistent with the
+// rules or if the resulting combination of traits has already been generated.
+//
+// The distribution of traits in the Collection will therefore differ from
+// that implied by the Weights if rules are particularly restrictive or if
+// MaxSupply is close to the number of possible combinations.
+func (s *GenerationSpec) Generate(rng *rand.Rand) (Collection, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	maxAttempts := s.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultMaxGenerationAttempts
+	}
+
+	coll := make(Collection)
+	seen := make(map[string]bool)
+	for i := 0; i < s.MaxSupply; i++ {
+		id := new(big.Int).SetUint64(s.FirstTokenID)
+		id.Add(id, big.NewInt(int64(i)))
+		tokenID, err := TokenIDFromBig(id)
+		if err != nil {
+			return nil, err
+		}
+
+		var md *Metadata
+		for attempt := 0; md == nil; attempt++ {
+			if attempt == maxAttempts {
+				return nil, fmt.Errorf("token %s: no unique combination of traits satisfying all rules after %d attempts", tokenID, maxAttempts)
+			}
+
+			selected, ok := s.selectTraits(rng)
+			if !ok {
+				continue
+			}
+			key := combinationKey(selected)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			md = s.metadata(selected)
+		}
+		coll[*tokenID] = md
+	}
+	return coll, nil
+}
+
+// selectTraits selects one value for every trait, returning them in the order
+// of s.Traits. It returns false if any trait has no value that satisfies all
+// rules.
+func (s *GenerationSpec) selectTraits(rng *rand.Rand) ([]string, bool) {
+	selected := make(map[string]string)
+	out := make([]string, 0, len(s.Traits))
+
+	for _, t := range s.Traits {
+		var (
+			allowed []*ValueSpec
+			total   float64
+		)
+		for _, v := range t.Values {
+			if v.Weight > 0 && s.allowed(selected, TraitValue{t.TraitType, v.Value}) {
+				allowed = append(allowed, v)
+				total += v.Weight
+			}
+		}
+		if len(allowed) == 0 {
+			return nil, false
+		}
+
+		choice := allowed[len(allowed)-1]
+		r := rng.Float64() * total
+		for _, v := range allowed {
+			if r < v.Weight {
+				choice = v
+				break
+			}
+			r -= v.Weight
+		}
+
+		selected[t.TraitType] = choice.Value
+		out = append(out, choice.Value)
+	}
+	return out, true
+}
+
+// allowed returns whether the candidate trait value is consistent with all
+// rules, given the already-selected values.
+func (s *GenerationSpec) allowed(selected map[string]string, candidate TraitValue) bool {
+	has := func(v TraitValue) bool {
+		if v == candidate {
+			return true
+		}
+		got, ok := selected[v.TraitType]
+		return ok && got == v.Value
+	}
+
+	for _, inc := range s.Incompatible {
+		n := 0
+		for _, v := range inc {
+			if has(v) {
+				n++
+			}
+		}
+		if n > 1 {
+			return false
+		}
+	}
+
+	for _, d := range s.Dependencies {
+		var then string
+		switch {
+		case has(d.If) && d.Then == candidate.TraitType:
+			then = candidate.Value
+		case has(d.If):
+			var ok bool
+			if then, ok = selected[d.Then]; !ok {
+				// Not yet selected, so it will be checked when it is.
+				continue
+			}
+		default:
+			continue
+		}
+
+		ok := false
+		for _, v := range d.Values {
+			if v == then {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// combinationKey returns a unique string representation of the selected trait
+// values.
+func combinationKey(selected []string) string {
+	quoted := make([]string, len(selected))
+	for i, v := range selected {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return strings.Join(quoted, ",")
+}
+
+// metadata returns Metadata with an Attribute for each of the selected
+// values, in the order of s.Traits, omitting empty values.
+func (s *GenerationSpec) metadata(selected []string) *Metadata {
+	md := new(Metadata)
+	for i, v := range selected {
+		if v == "" {
+			continue
+		}
+		md.Attributes = append(md.Attributes, &Attribute{
+			TraitType: s.Traits[i].TraitType,
+			Value:     v,
+		})
+	}
+	return md
+}
@@ -0,0 +1,267 @@
+package erc721
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+)
+
+// values returns ValueSpecs with unit weights.
+func values(vals ...string) []*ValueSpec {
+	var v []*ValueSpec
+	for _, val := range vals {
+		v = append(v, &ValueSpec{Value: val, Weight: 1})
+	}
+	return v
+}
+
+// traitValues returns the value of each TraitType carried by md.
+func traitValues(md *Metadata) map[string]string {
+	vals := make(map[string]string)
+	for _, a := range md.Attributes {
+		vals[a.TraitType] = a.Value.(string)
+	}
+	return vals
+}
+
+func TestGenerate(t *testing.T) {
+	spec := &GenerationSpec{
+		MaxSupply:    12,
+		FirstTokenID: 1,
+		Traits: []*TraitSpec{
+			{TraitType: "Background", Values: values("Blue", "Gold", "Red")},
+			{TraitType: "Body", Values: values("Robot", "Zombie", "Human")},
+			{TraitType: "Hat", Values: values("", "Crown", "Cap")},
+		},
+		Incompatible: [][]TraitValue{{
+			{TraitType: "Body", Value: "Robot"},
+			{TraitType: "Hat", Value: "Cap"},
+		}},
+		Dependencies: []*TraitDependency{
+			{
+				// The dependent trait is selected earlier.
+				If:     TraitValue{TraitType: "Hat", Value: "Crown"},
+				Then:   "Background",
+				Values: []string{"Gold"},
+			},
+			{
+				// The dependent trait is selected later.
+				If:     TraitValue{TraitType: "Body", Value: "Zombie"},
+				Then:   "Hat",
+				Values: []string{""},
+			},
+		},
+	}
+
+	got, err := spec.Generate(rand.New(rand.NewSource(42)))
+	if err != nil {
+		t.Fatalf("%T.Generate() error %v", spec, err)
+	}
+	if n := len(got); n != spec.MaxSupply {
+		t.Fatalf("%T.Generate() got %d tokens; want %d", spec, n, spec.MaxSupply)
+	}
+
+	seen := make(map[string]bool)
+	for i := 1; i <= spec.MaxSupply; i++ {
+		md, ok := got[*TokenIDFromInt(i)]
+		if !ok {
+			t.Fatalf("%T.Generate() missing token %d", spec, i)
+		}
+		vals := traitValues(md)
+
+		key, err := json.Marshal(vals)
+		if err != nil {
+			t.Fatalf("json.Marshal(%T) error %v", vals, err)
+		}
+		if seen[string(key)] {
+			t.Errorf("Token %d has duplicate combination of traits %s", i, key)
+		}
+		seen[string(key)] = true
+
+		if vals["Body"] == "Robot" && vals["Hat"] == "Cap" {
+			t.Errorf("Token %d has incompatible traits %v", i, vals)
+		}
+		if vals["Hat"] == "Crown" && vals["Background"] != "Gold" {
+			t.Errorf("Token %d has Crown without Gold Background; %v", i, vals)
+		}
+		if _, ok := vals["Hat"]; ok && vals["Body"] == "Zombie" {
+			t.Errorf("Token %d has Zombie with Hat; %v", i, vals)
+		}
+	}
+
+	t.Run("deterministic", func(t *testing.T) {
+		again, err := spec.Generate(rand.New(rand.NewSource(42)))
+		if err != nil {
+			t.Fatalf("%T.Generate() error %v", spec, err)
+		}
+		if diff := cmp.Diff(got, again); diff != "" {
+			t.Errorf("%T.Generate() with same seed diff (-first +second):\n%s", spec, diff)
+		}
+
+		other, err := spec.Generate(rand.New(rand.NewSource(43)))
+		if err != nil {
+			t.Fatalf("%T.Generate() error %v", spec, err)
+		}
+		if cmp.Equal(got, other) {
+			t.Errorf("%T.Generate() with different seeds returned identical Collections", spec)
+		}
+	})
+}
+
+func TestGenerateAllCombinations(t *testing.T) {
+	spec := &GenerationSpec{
+		MaxSupply: 6,
+		Traits: []*TraitSpec{
+			{TraitType: "a", Values: values("x", "y")},
+			{TraitType: "b", Values: []*ValueSpec{
+				{Value: "1", Weight: 100},
+				{Value: "2", Weight: 1},
+				{Value: "3", Weight: 1},
+			}},
+		},
+	}
+
+	got, err := spec.Generate(rand.New(rand.NewSource(0)))
+	if err != nil {
+		t.Fatalf("%T.Generate() error %v", spec, err)
+	}
+
+	seen := make(map[[2]string]bool)
+	for id, md := range got {
+		vals := traitValues(md)
+		seen[[2]string{vals["a"], vals["b"]}] = true
+		if id.Cmp(TokenIDFromInt(6)) >= 0 {
+			t.Errorf("%T.Generate() returned token %s; want [0,6)", spec, &id)
+		}
+	}
+	if n := len(seen); n != 6 {
+		t.Errorf("%T.Generate() with MaxSupply = all combinations got %d unique combinations; want 6", spec, n)
+	}
+}
+
+func TestGenerateWeights(t *testing.T) {
+	spec := &GenerationSpec{
+		MaxSupply: 1000,
+		Traits: []*TraitSpec{
+			{TraitType: "a", Values: []*ValueSpec{
+				{Value: "common", Weight: 3},
+				{Value: "rare", Weight: 1},
+				{Value: "never", Weight: 0},
+			}},
+			// Sufficient combinations that uniqueness doesn't skew the
+			// distribution of a.
+			{TraitType: "b", Values: values("0", "1", "2", "3", "4", "5", "6", "7", "8", "9")},
+			{TraitType: "c", Values: values("0", "1", "2", "3", "4", "5", "6", "7", "8", "9")},
+			{TraitType: "d", Values: values("0", "1", "2", "3", "4", "5", "6", "7", "8", "9")},
+			{TraitType: "e", Values: values("0", "1", "2", "3", "4", "5", "6", "7", "8", "9")},
+		},
+	}
+
+	coll, err := spec.Generate(rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("%T.Generate() error %v", spec, err)
+	}
+
+	counts := make(map[string]int)
+	for _, md := range coll {
+		counts[traitValues(md)["a"]]++
+	}
+	if counts["never"] != 0 {
+		t.Errorf("Zero-weight value selected %d times", counts["never"])
+	}
+	if got := float64(counts["common"]) / float64(spec.MaxSupply); got < 0.7 || got > 0.8 {
+		t.Errorf("Value with 3/4 of weight selected in proportion %.3f of tokens; want ~0.75", got)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	traits := func() []*TraitSpec {
+		return []*TraitSpec{
+			{TraitType: "a", Values: values("x", "y")},
+			{TraitType: "b", Values: values("1", "2")},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		spec           *GenerationSpec
+		errDiffAgainst interface{}
+	}{
+		{
+			name:           "zero supply",
+			spec:           &GenerationSpec{Traits: traits()},
+			errDiffAgainst: "max_supply must be positive",
+		},
+		{
+			name:           "supply exceeds combinations",
+			spec:           &GenerationSpec{MaxSupply: 5, Traits: traits()},
+			errDiffAgainst: "exceeds number of possible combinations 4",
+		},
+		{
+			name: "duplicate trait type",
+			spec: &GenerationSpec{
+				MaxSupply: 1,
+				Traits:    append(traits(), &TraitSpec{TraitType: "a", Values: values("z")}),
+			},
+			errDiffAgainst: `trait "a": duplicate trait_type`,
+		},
+		{
+			name: "duplicate value",
+			spec: &GenerationSpec{
+				MaxSupply: 1,
+				Traits:    []*TraitSpec{{TraitType: "a", Values: values("x", "x")}},
+			},
+			errDiffAgainst: `duplicate value "x"`,
+		},
+		{
+			name: "zero total weight",
+			spec: &GenerationSpec{
+				MaxSupply: 1,
+				Traits:    []*TraitSpec{{TraitType: "a", Values: []*ValueSpec{{Value: "x"}}}},
+			},
+			errDiffAgainst: "total weight must be positive",
+		},
+		{
+			name: "unknown incompatible value",
+			spec: &GenerationSpec{
+				MaxSupply:    1,
+				Traits:       traits(),
+				Incompatible: [][]TraitValue{{{"a", "x"}, {"b", "3"}}},
+			},
+			errDiffAgainst: `incompatible[0]: unknown trait value "b"="3"`,
+		},
+		{
+			name: "unknown dependency",
+			spec: &GenerationSpec{
+				MaxSupply:    1,
+				Traits:       traits(),
+				Dependencies: []*TraitDependency{{If: TraitValue{"c", "x"}, Then: "a", Values: []string{"x"}}},
+			},
+			errDiffAgainst: `dependencies[0]: unknown trait value "c"="x"`,
+		},
+		{
+			name: "rules prevent supply",
+			spec: &GenerationSpec{
+				MaxSupply: 4,
+				Traits:    traits(),
+				Incompatible: [][]TraitValue{
+					{{"a", "x"}, {"b", "1"}},
+				},
+				MaxAttempts: 50,
+			},
+			errDiffAgainst: "no unique combination of traits satisfying all rules after 50 attempts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.spec.Generate(rand.New(rand.NewSource(0)))
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Errorf("%T.Generate() %s", tt.spec, diff)
+			}
+		})
+	}
+}
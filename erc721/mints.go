@@ -0,0 +1,88 @@
+package erc721
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TransferEventTopic is the topic of the ERC721 Transfer(address,address,uint256)
+// event, all parameters of which are indexed.
+var TransferEventTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// A MintTracker maintains the set of minted tokens of a single contract by
+// watching for Transfer events from the zero address. It is an alternative to
+// calling ownerOf for every request to a Server, and is consistent with the
+// chain as seen by the node; e.g. if used in a delayed reveal, tokens are
+// revealed as soon as the mint is observed.
+//
+// Tokens are tracked as minted even if they are later burned, mirroring the
+// existence semantics of Server.Cache. Mints that are removed due to a chain
+// reorganisation are, however, no longer tracked.
+type MintTracker struct {
+	*logWatcher
+
+	mu     sync.RWMutex
+	minted map[TokenID]bool
+}
+
+// NewMintTracker subscribes to the contract's mint events and then backfills
+// all mints since fromBlock, returning once the backfill is complete. The
+// context is only used for the initial subscription and backfill; the
+// subscription remains active until Close() is called or it fails (see Err()).
+func NewMintTracker(ctx context.Context, filterer bind.ContractFilterer, contract common.Address, fromBlock uint64) (*MintTracker, error) {
+	q := ethereum.FilterQuery{
+		Addresses: []common.Address{contract},
+		Topics: [][]common.Hash{
+			{TransferEventTopic},
+			{common.Hash{}}, // from
+		},
+	}
+
+	t := &MintTracker{
+		minted: make(map[TokenID]bool),
+	}
+	w, err := watchLogs(ctx, filterer, q, &fromBlock, t.handle)
+	if err != nil {
+		return nil, err
+	}
+	t.logWatcher = w
+	return t, nil
+}
+
+// handle records the token minted in the log, or removes it if the log was
+// reverted by a reorg. Logs that aren't ERC721 mints, like ERC20 Transfers
+// with only 3 topics, are ignored.
+func (t *MintTracker) handle(l types.Log) {
+	if len(l.Topics) != 4 || l.Topics[0] != TransferEventTopic || l.Topics[1] != (common.Hash{}) {
+		return
+	}
+	id := TokenID(l.Topics[3])
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if l.Removed {
+		delete(t.minted, id)
+	} else {
+		t.minted[id] = true
+	}
+}
+
+// Minted reports whether the token has been minted.
+func (t *MintTracker) Minted(id *TokenID) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.minted[*id]
+}
+
+// Count returns the number of minted tokens.
+func (t *MintTracker) Count() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.minted)
+}
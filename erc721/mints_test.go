@@ -0,0 +1,161 @@
+package erc721
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/julienschmidt/httprouter"
+
+	contract "github.com/divergencetech/ethier/tests/erc721"
+)
+
+// deployTestable deploys a TestableERC721ACommon, returning its address and
+// binding.
+func deployTestable(t *testing.T, sim *ethtest.SimulatedBackend) (common.Address, *contract.TestableERC721ACommon) {
+	t.Helper()
+	addr, _, nft, err := contract.DeployTestableERC721ACommon(sim.Acc(deployer), sim, sim.Addr(admin), sim.Addr(steerer), common.Address{1}, big.NewInt(0))
+	if err != nil {
+		t.Fatalf("DeployTestableERC721ACommon(): %v", err)
+	}
+	return addr, nft
+}
+
+// waitForCount waits for the tracker to have the specified number of mints,
+// reporting an error if this doesn't happen in a timely manner.
+func waitForCount(t *testing.T, tracker *MintTracker, want int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for tracker.Count() != want && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := tracker.Count(); got != want {
+		t.Errorf("%T.Count() got %d; want %d", tracker, got, want)
+	}
+}
+
+func TestMintTracker(t *testing.T) {
+	ctx := context.Background()
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	addr, nft := deployTestable(t, sim)
+	_, other := deployTestable(t, sim)
+
+	// Backfilled mints of tokens [0,3).
+	startBlock := sim.BlockNumber().Uint64()
+	sim.Must(t, "%T.MintN(3)", nft)(nft.MintN(sim.Acc(deployer), big.NewInt(3)))
+	// Tokens [0,5) of another contract, which must be ignored.
+	sim.Must(t, "%T.MintN(5) [other contract]", other)(other.MintN(sim.Acc(deployer), big.NewInt(5)))
+
+	tracker, err := NewMintTracker(ctx, sim, addr, startBlock)
+	if err != nil {
+		t.Fatalf("NewMintTracker() error %v", err)
+	}
+	t.Cleanup(tracker.Close)
+
+	if got, want := tracker.Count(), 3; got != want {
+		t.Errorf("%T.Count() after backfill got %d; want %d", tracker, got, want)
+	}
+
+	// Transfer events that aren't mints must be ignored, as must mints by the
+	// other contract. A final mint is emitted last so waitForCount() only
+	// returns after all other events have been handled.
+	sim.Must(t, "%T.Burn(1)", nft)(nft.Burn(sim.Acc(deployer), big.NewInt(1)))
+	sim.Must(t, "%T.TransferFrom(2)", nft)(nft.TransferFrom(sim.Acc(deployer), sim.Addr(deployer), sim.Addr(admin), big.NewInt(2)))
+	sim.Must(t, "%T.Mint() [other contract]", other)(other.Mint(sim.Acc(deployer)))
+	sim.Must(t, "%T.Mint()", nft)(nft.Mint(sim.Acc(deployer)))
+	waitForCount(t, tracker, 4)
+
+	for id, want := range map[int]bool{
+		0: true,
+		1: true, // burned
+		2: true, // transferred
+		3: true,
+		4: false, // minted by other contract
+		5: false, // minted by other contract
+	} {
+		if got := tracker.Minted(TokenIDFromInt(id)); got != want {
+			t.Errorf("%T.Minted(%d) got %t; want %t", tracker, id, got, want)
+		}
+	}
+
+	if err := tracker.Err(); err != nil {
+		t.Errorf("%T.Err() got %v; want nil", tracker, err)
+	}
+}
+
+func TestServerWithMintTracker(t *testing.T) {
+	ctx := context.Background()
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	addr, nft := deployTestable(t, sim)
+	sim.Must(t, "%T.Mint()", nft)(nft.Mint(sim.Acc(deployer)))
+
+	tracker, err := NewMintTracker(ctx, sim, addr, 0)
+	if err != nil {
+		t.Fatalf("NewMintTracker() error %v", err)
+	}
+	t.Cleanup(tracker.Close)
+
+	// The Contract would return an error for all tokens, demonstrating that
+	// it isn't used.
+	fake := &fakeContract{}
+	srv := &Server{
+		Contract:        fake,
+		Mints:           tracker,
+		MintedCountPath: "/minted",
+		Metadata: []MetadataEndpoint{{
+			Path: "/metadata/:tokenId",
+			Handler: func(_ Interface, id *TokenID, _ httprouter.Params) (*Metadata, int, error) {
+				return &Metadata{Name: fmt.Sprintf("Token %s", id)}, 200, nil
+			},
+		}},
+	}
+	baseURL := start(t, srv)
+
+	wantCode := func(t *testing.T, id, want int) {
+		t.Helper()
+		url := fmt.Sprintf("%s/metadata/%d", baseURL, id)
+		resp := httpGet(t, url)
+		resp.Body.Close()
+		if got := resp.StatusCode; got != want {
+			t.Errorf("HTTP GET %q got code %d; want %d", url, got, want)
+		}
+	}
+	wantMinted := func(t *testing.T, want int) {
+		t.Helper()
+		url := baseURL + "/minted"
+		resp := httpGet(t, url)
+		defer resp.Body.Close()
+		var got struct{ Minted int }
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatalf("json.Decode(HTTP GET %q) error %v", url, err)
+		}
+		if got.Minted != want {
+			t.Errorf("HTTP GET %q got minted = %d; want %d", url, got.Minted, want)
+		}
+	}
+
+	wantCode(t, 0, 200)
+	wantCode(t, 1, 404)
+	wantMinted(t, 1)
+
+	sim.Must(t, "%T.Mint()", nft)(nft.Mint(sim.Acc(deployer)))
+	waitForCount(t, tracker, 2)
+	wantCode(t, 1, 200)
+	wantMinted(t, 2)
+
+	if got := fake.calls(); got != 0 {
+		t.Errorf("%T.OwnerOf() called %d times; want 0 when using %T", fake, got, tracker)
+	}
+}
+
+func TestMintedCountPathRequiresTracker(t *testing.T) {
+	srv := &Server{MintedCountPath: "/minted"}
+	if _, err := srv.Handler(); err == nil {
+		t.Errorf("%T{MintedCountPath: %q, Mints: nil}.Handler() got nil error; want error", srv, srv.MintedCountPath)
+	}
+}
@@ -0,0 +1,110 @@
+package erc721
+
+import (
+	"github.com/julienschmidt/httprouter"
+)
+
+// Default TraitTypes of Attributes added by RarityAttributes.
+const (
+	DefaultRarityRankTraitType  = "Rarity Rank"
+	DefaultRarityScoreTraitType = "Rarity Score"
+)
+
+// RarityAttributes annotates Metadata with Attributes describing each token's
+// rarity rank and score, typically as computed by Collection.Rarity() or a
+// RarityModel.
+type RarityAttributes struct {
+	// RankTraitType and ScoreTraitType are the TraitTypes of the added
+	// Attributes, defaulting to DefaultRarityRankTraitType and
+	// DefaultRarityScoreTraitType respectively if empty. Existing Attributes
+	// with either TraitType are replaced.
+	RankTraitType, ScoreTraitType string
+	// OmitScore, if true, results in only the rank being added.
+	OmitScore bool
+	// DisplayNumber, if true, sets the DisplayType of added Attributes to
+	// DisplayNumber; otherwise they use DisplayDefault.
+	DisplayNumber bool
+
+	ranked map[TokenID]RankedToken
+}
+
+// NewRarityAttributes returns a RarityAttributes that annotates tokens with
+// their ranks and scores; e.g. NewRarityAttributes(coll.Rarity(nil).Scores).
+// Ranks are computed with RankRarity().
+func NewRarityAttributes(scores map[TokenID]float64) *RarityAttributes {
+	ranked := make(map[TokenID]RankedToken)
+	for _, r := range RankRarity(scores) {
+		ranked[r.ID] = r
+	}
+	return &RarityAttributes{ranked: ranked}
+}
+
+// Annotate returns a copy of md with rank and score Attributes added. The
+// original Metadata is not modified. If the token has no score, md is returned
+// unchanged; this allows for tokens minted after rarity was computed.
+func (a *RarityAttributes) Annotate(id *TokenID, md *Metadata) *Metadata {
+	r, ok := a.ranked[*id]
+	if !ok || md == nil {
+		return md
+	}
+
+	rankType := a.RankTraitType
+	if rankType == "" {
+		rankType = DefaultRarityRankTraitType
+	}
+	scoreType := a.ScoreTraitType
+	if scoreType == "" {
+		scoreType = DefaultRarityScoreTraitType
+	}
+	var display OpenSeaDisplayType
+	if a.DisplayNumber {
+		display = DisplayNumber
+	}
+
+	out := *md
+	out.Attributes = make([]*Attribute, 0, len(md.Attributes)+2)
+	for _, attr := range md.Attributes {
+		if attr != nil && (attr.TraitType == rankType || attr.TraitType == scoreType) {
+			continue
+		}
+		out.Attributes = append(out.Attributes, attr)
+	}
+
+	out.Attributes = append(out.Attributes, &Attribute{
+		TraitType:   rankType,
+		Value:       r.Rank,
+		DisplayType: display,
+	})
+	if !a.OmitScore {
+		out.Attributes = append(out.Attributes, &Attribute{
+			TraitType:   scoreType,
+			Value:       r.Score,
+			DisplayType: display,
+		})
+	}
+	return &out
+}
+
+// AnnotateCollection returns a new Collection with every token's Metadata
+// passed through Annotate().
+func (a *RarityAttributes) AnnotateCollection(coll Collection) Collection {
+	out := make(Collection)
+	for id, md := range coll {
+		id := id
+		out[id] = a.Annotate(&id, md)
+	}
+	return out
+}
+
+// MetadataHandler returns a MetadataHandler that wraps h, passing all Metadata
+// that it returns through Annotate(). This allows rarity to be served by a
+// Server via the same code path as the rest of the Metadata.
+func (a *RarityAttributes) MetadataHandler(h MetadataHandler) MetadataHandler {
+	return func(i Interface, id *TokenID, params httprouter.Params) (*Metadata, int, error) {
+		md, code, err := h(i, id, params)
+		if err != nil {
+			return md, code, err
+		}
+		return a.Annotate(id, md), code, nil
+	}
+}
@@ -0,0 +1,156 @@
+package erc721
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestRarityAttributes(t *testing.T) {
+	scores := map[TokenID]float64{
+		*TokenIDFromInt(0): 2,
+		*TokenIDFromInt(1): 3,
+		*TokenIDFromInt(2): 2,
+	}
+	orig := &Metadata{
+		Name: "Token",
+		Attributes: []*Attribute{
+			{TraitType: "Colour", Value: "Red"},
+			{TraitType: "Rarity Rank", Value: "stale"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		config func(*RarityAttributes)
+		id     *TokenID
+		want   []*Attribute
+	}{
+		{
+			name: "defaults",
+			id:   TokenIDFromInt(0),
+			want: []*Attribute{
+				{TraitType: "Colour", Value: "Red"},
+				{TraitType: DefaultRarityRankTraitType, Value: 2},
+				{TraitType: DefaultRarityScoreTraitType, Value: 2.},
+			},
+		},
+		{
+			name: "custom trait types with DisplayNumber",
+			config: func(a *RarityAttributes) {
+				a.RankTraitType = "Rank"
+				a.ScoreTraitType = "Score"
+				a.DisplayNumber = true
+			},
+			id: TokenIDFromInt(1),
+			want: []*Attribute{
+				{TraitType: "Colour", Value: "Red"},
+				{TraitType: "Rarity Rank", Value: "stale"},
+				{TraitType: "Rank", Value: 1, DisplayType: DisplayNumber},
+				{TraitType: "Score", Value: 3., DisplayType: DisplayNumber},
+			},
+		},
+		{
+			name:   "omit score",
+			config: func(a *RarityAttributes) { a.OmitScore = true },
+			id:     TokenIDFromInt(2),
+			want: []*Attribute{
+				{TraitType: "Colour", Value: "Red"},
+				{TraitType: DefaultRarityRankTraitType, Value: 2},
+			},
+		},
+		{
+			name: "unscored token",
+			id:   TokenIDFromInt(3),
+			want: orig.Attributes,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewRarityAttributes(scores)
+			if tt.config != nil {
+				tt.config(a)
+			}
+			var before []Attribute
+			for _, attr := range orig.Attributes {
+				before = append(before, *attr)
+			}
+
+			got := a.Annotate(tt.id, orig)
+			if diff := cmp.Diff(tt.want, got.Attributes); diff != "" {
+				t.Errorf("%T.Annotate(%s).Attributes diff (-want +got):\n%s", a, tt.id, diff)
+			}
+			if got.Name != orig.Name {
+				t.Errorf("%T.Annotate(%s).Name got %q; want %q", a, tt.id, got.Name, orig.Name)
+			}
+			var after []Attribute
+			for _, attr := range orig.Attributes {
+				after = append(after, *attr)
+			}
+			if diff := cmp.Diff(before, after); diff != "" {
+				t.Errorf("%T.Annotate(%s) modified original Metadata; diff (-before +after):\n%s", a, tt.id, diff)
+			}
+		})
+	}
+}
+
+func TestRarityAttributesCollection(t *testing.T) {
+	coll := Collection{
+		*TokenIDFromInt(0): md(t, "key", "a"),
+		*TokenIDFromInt(1): md(t, "key", "b"),
+		*TokenIDFromInt(2): md(t, "key", "a"),
+	}
+	a := NewRarityAttributes(coll.Rarity(nil).Scores)
+	got := a.AnnotateCollection(coll)
+
+	want := map[int]int{0: 2, 1: 1, 2: 2}
+	for id, rank := range want {
+		attrs := got[*TokenIDFromInt(id)].Attributes
+		if n := len(attrs); n != 3 {
+			t.Fatalf("Token %d got %d Attributes; want 3", id, n)
+		}
+		if got, want := attrs[1], (&Attribute{TraitType: DefaultRarityRankTraitType, Value: rank}); !cmp.Equal(got, want) {
+			t.Errorf("Token %d rank Attribute got %+v; want %+v", id, got, want)
+		}
+	}
+	if n := len(coll[*TokenIDFromInt(0)].Attributes); n != 1 {
+		t.Errorf("%T.AnnotateCollection() modified original; got %d Attributes; want 1", a, n)
+	}
+}
+
+func TestServerWithRarityAttributes(t *testing.T) {
+	a := NewRarityAttributes(map[TokenID]float64{
+		*TokenIDFromInt(7): 1.5,
+	})
+	a.DisplayNumber = true
+
+	srv := &Server{
+		Metadata: []MetadataEndpoint{{
+			Path: "/metadata/:tokenId",
+			Handler: a.MetadataHandler(func(_ Interface, id *TokenID, _ httprouter.Params) (*Metadata, int, error) {
+				return &Metadata{Name: fmt.Sprintf("Token %s", id)}, 200, nil
+			}),
+		}},
+	}
+	baseURL := start(t, srv)
+
+	resp := httpGet(t, baseURL+"/metadata/7")
+	defer resp.Body.Close()
+	testContentType(t, resp, "application/json")
+
+	got := metadataFromResponse(t, resp)
+	want := &Metadata{
+		Name: "Token 7",
+		Attributes: []*Attribute{
+			// JSON numbers are decoded as float64.
+			{TraitType: DefaultRarityRankTraitType, Value: 1., DisplayType: DisplayNumber},
+			{TraitType: DefaultRarityScoreTraitType, Value: 1.5, DisplayType: DisplayNumber},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GET /metadata/7 diff (-want +got):\n%s", diff)
+	}
+}
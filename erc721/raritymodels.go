@@ -0,0 +1,354 @@
+package erc721
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Traits describes the distribution of Attribute values across a Collection,
+// with each TraitType considered as a categorical distribution. It is the
+// input to all RarityModels.
+type Traits struct {
+	// Size is the number of tokens in the Collection.
+	Size int
+	// Values maps every token to the value of each TraitType that it carries.
+	// If a token has more than one Attribute with the same TraitType, the last
+	// one is used.
+	Values map[TokenID]map[string]string
+	// Counts maps every TraitType to the number of tokens carrying each of its
+	// values.
+	Counts map[string]map[string]int
+}
+
+// Traits computes the distribution of Attribute values across the Collection.
+// Non-string Values are passed to the bucket function, as for
+// Collection.Rarity(); if bucket is nil, fmt.Sprint() is used. See
+// BucketedTraits() for more sophisticated bucketing.
+func (coll Collection) Traits(bucket func(interface{}) string) *Traits {
+	if bucket == nil {
+		bucket = func(x interface{}) string {
+			return fmt.Sprint(x)
+		}
+	}
+	t, _ := coll.traits(func(a *Attribute) (string, error) {
+		return bucket(a.Value), nil
+	})
+	return t
+}
+
+// traits computes the distribution of Attribute values across the Collection,
+// passing all Attributes with non-string Values to the bucket function.
+func (coll Collection) traits(bucket func(*Attribute) (string, error)) (*Traits, error) {
+	t := &Traits{
+		Size:   len(coll),
+		Values: make(map[TokenID]map[string]string),
+		Counts: make(map[string]map[string]int),
+	}
+	for id, meta := range coll {
+		vals := make(map[string]string)
+		t.Values[id] = vals
+		if meta == nil {
+			continue
+		}
+		for _, attr := range meta.Attributes {
+			if attr == nil {
+				continue
+			}
+			val, ok := attr.Value.(string)
+			if !ok {
+				var err error
+				if val, err = bucket(attr); err != nil {
+					return nil, fmt.Errorf("token %s: %v", &id, err)
+				}
+			}
+			vals[attr.TraitType] = val
+		}
+
+		for traitType, val := range vals {
+			if _, ok := t.Counts[traitType]; !ok {
+				t.Counts[traitType] = make(map[string]int)
+			}
+			t.Counts[traitType][val]++
+		}
+	}
+	return t, nil
+}
+
+// TraitTypes returns all TraitTypes in lexicographical order. Models iterate
+// over TraitTypes in this order so that tokens with identical traits have
+// identical floating-point scores.
+func (t *Traits) TraitTypes() []string {
+	types := make([]string, 0, len(t.Counts))
+	for tt := range t.Counts {
+		types = append(types, tt)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Carrying returns the number of tokens that carry the TraitType.
+func (t *Traits) Carrying(traitType string) int {
+	var n int
+	for _, c := range t.Counts[traitType] {
+		n += c
+	}
+	return n
+}
+
+// NullCount returns the number of tokens that lack the TraitType; i.e. that
+// implicitly have a null value.
+func (t *Traits) NullCount(traitType string) int {
+	return t.Size - t.Carrying(traitType)
+}
+
+// Count returns the number of tokens that have the same value for the
+// TraitType as the specified token, including the token itself. If the token
+// lacks the TraitType, the null count is returned.
+func (t *Traits) Count(id TokenID, traitType string) int {
+	if v, ok := t.Values[id][traitType]; ok {
+		return t.Counts[traitType][v]
+	}
+	return t.NullCount(traitType)
+}
+
+// Probability returns Count(id, traitType) / Size.
+func (t *Traits) Probability(id TokenID, traitType string) float64 {
+	return float64(t.Count(id, traitType)) / float64(t.Size)
+}
+
+// Entropy returns the sum of the entropies, in bits, of every TraitType's
+// distribution, including null values. It is equal to Collection.Rarity()'s
+// Entropy.
+func (t *Traits) Entropy() float64 {
+	n := float64(t.Size)
+	var entropy float64
+	for _, tt := range t.TraitTypes() {
+		counts := make([]int, 0, len(t.Counts[tt])+1)
+		for _, c := range t.Counts[tt] {
+			counts = append(counts, c)
+		}
+		counts = append(counts, t.NullCount(tt))
+		sort.Ints(counts)
+
+		for _, c := range counts {
+			if c == 0 {
+				continue
+			}
+			p := float64(c) / n
+			entropy += -p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+// withTraitCount returns a copy of t with an additional TraitType, keyed by
+// name, carried by every token and with a value equal to the number of other
+// TraitTypes that the token carries.
+func (t *Traits) withTraitCount(name string) *Traits {
+	out := &Traits{
+		Size:   t.Size,
+		Values: make(map[TokenID]map[string]string),
+		Counts: make(map[string]map[string]int),
+	}
+	for tt, counts := range t.Counts {
+		out.Counts[tt] = counts
+	}
+	out.Counts[name] = make(map[string]int)
+
+	for id, vals := range t.Values {
+		n := strconv.Itoa(len(vals))
+		out.Values[id] = make(map[string]string)
+		for tt, v := range vals {
+			out.Values[id][tt] = v
+		}
+		out.Values[id][name] = n
+		out.Counts[name][n]++
+	}
+	return out
+}
+
+// A RarityModel computes a rarity score for every token described by the
+// Traits. Higher scores MUST indicate greater rarity.
+type RarityModel interface {
+	Scores(*Traits) map[TokenID]float64
+}
+
+// A TraitsTransformer is implemented by RarityModels that don't score the
+// Traits that they are passed, but a transformation of them; e.g. OpenRarity
+// adds a meta trait.
+type TraitsTransformer interface {
+	// EffectiveTraits returns the Traits that are scored by the model when
+	// passed t.
+	EffectiveTraits(t *Traits) *Traits
+}
+
+// EffectiveTraits returns the Traits that are scored by the model when passed
+// t; i.e. model.EffectiveTraits(t) if the model is a TraitsTransformer,
+// otherwise t itself. The result is suitable for NewRarityReport().
+func EffectiveTraits(model RarityModel, t *Traits) *Traits {
+	if tt, ok := model.(TraitsTransformer); ok {
+		return tt.EffectiveTraits(t)
+	}
+	return t
+}
+
+// RarityModels are all RarityModels provided by this package, keyed by name.
+var RarityModels = map[string]RarityModel{
+	"information":   InformationRarity{},
+	"trait-count":   TraitCountRarity{},
+	"statistical":   StatisticalRarity{},
+	"average-trait": AverageTraitRarity{},
+	"openrarity":    OpenRarity{},
+}
+
+// InformationRarity is the information-theoretic RarityModel used by
+// Collection.Rarity(): the sum of the self-information of each of a token's
+// traits, including null values, divided by the Collection's entropy.
+type InformationRarity struct{}
+
+// Scores implements RarityModel.
+func (InformationRarity) Scores(t *Traits) map[TokenID]float64 {
+	entropy := t.Entropy()
+	types := t.TraitTypes()
+
+	scores := make(map[TokenID]float64)
+	for id := range t.Values {
+		var s float64
+		for _, tt := range types {
+			s += -math.Log2(t.Probability(id, tt))
+		}
+		if entropy > 0 {
+			s /= entropy
+		}
+		scores[id] = s
+	}
+	return scores
+}
+
+// TraitCountRarity scores tokens only by the rarity of the number of traits
+// that they carry: the reciprocal of the proportion of tokens carrying the
+// same number of traits.
+type TraitCountRarity struct{}
+
+// Scores implements RarityModel.
+func (TraitCountRarity) Scores(t *Traits) map[TokenID]float64 {
+	counts := make(map[int]int)
+	for _, vals := range t.Values {
+		counts[len(vals)]++
+	}
+
+	scores := make(map[TokenID]float64)
+	for id, vals := range t.Values {
+		scores[id] = float64(t.Size) / float64(counts[len(vals)])
+	}
+	return scores
+}
+
+// StatisticalRarity scores tokens by the reciprocal of the product of the
+// probabilities of each of their traits, including null values; i.e. the
+// inverse of the probability of a token's combination of traits, assuming
+// independence.
+type StatisticalRarity struct{}
+
+// Scores implements RarityModel.
+func (StatisticalRarity) Scores(t *Traits) map[TokenID]float64 {
+	types := t.TraitTypes()
+
+	scores := make(map[TokenID]float64)
+	for id := range t.Values {
+		p := 1.
+		for _, tt := range types {
+			p *= t.Probability(id, tt)
+		}
+		scores[id] = 1 / p
+	}
+	return scores
+}
+
+// AverageTraitRarity scores tokens by the reciprocal of the mean probability of
+// each of their traits, including null values.
+type AverageTraitRarity struct{}
+
+// Scores implements RarityModel.
+func (AverageTraitRarity) Scores(t *Traits) map[TokenID]float64 {
+	types := t.TraitTypes()
+
+	scores := make(map[TokenID]float64)
+	for id := range t.Values {
+		if len(types) == 0 {
+			scores[id] = 1
+			continue
+		}
+		var sum float64
+		for _, tt := range types {
+			sum += t.Probability(id, tt)
+		}
+		scores[id] = float64(len(types)) / sum
+	}
+	return scores
+}
+
+// OpenRarityTraitCountType is the TraitType of the meta trait added by
+// OpenRarity.
+const OpenRarityTraitCountType = "meta_trait:trait_count"
+
+// OpenRarity follows the OpenRarity standard (https://openrarity.dev):
+// InformationRarity computed with an additional meta trait equal to the number
+// of traits carried by each token.
+type OpenRarity struct{}
+
+// Scores implements RarityModel.
+func (m OpenRarity) Scores(t *Traits) map[TokenID]float64 {
+	return InformationRarity{}.Scores(m.EffectiveTraits(t))
+}
+
+// EffectiveTraits implements TraitsTransformer, adding the
+// OpenRarityTraitCountType meta trait.
+func (OpenRarity) EffectiveTraits(t *Traits) *Traits {
+	return t.withTraitCount(OpenRarityTraitCountType)
+}
+
+// A RankedToken is a token's rarity score and rank.
+type RankedToken struct {
+	ID    TokenID
+	Score float64
+	// Rank is the dense rank of the token, starting at 1 for the rarest
+	// token(s). Tokens with equal scores share a rank, and the rank of the
+	// next-rarest tokens is one greater.
+	Rank int
+}
+
+// RankRarity returns the scores of all tokens, ranked from rarest to most
+// common. Tokens with equal scores are ordered by TokenID.
+func RankRarity(scores map[TokenID]float64) []RankedToken {
+	ranked := make([]RankedToken, 0, len(scores))
+	for id, s := range scores {
+		ranked = append(ranked, RankedToken{ID: id, Score: s})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		rI, rJ := ranked[i], ranked[j]
+		if rI.Score != rJ.Score {
+			return rI.Score > rJ.Score
+		}
+		return rI.ID.Cmp(&rJ.ID) < 0
+	})
+
+	for i := range ranked {
+		switch {
+		case i == 0:
+			ranked[i].Rank = 1
+		case ranked[i].Score == ranked[i-1].Score:
+			ranked[i].Rank = ranked[i-1].Rank
+		default:
+			ranked[i].Rank = ranked[i-1].Rank + 1
+		}
+	}
+	return ranked
+}
+
+// RankRarity returns RankRarity(model.Scores(coll.Traits(bucket))).
+func (coll Collection) RankRarity(model RarityModel, bucket func(interface{}) string) []RankedToken {
+	return RankRarity(model.Scores(coll.Traits(bucket)))
+}
@@ -0,0 +1,180 @@
+package erc721
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestRarityModels(t *testing.T) {
+	coll := Collection{
+		*TokenIDFromInt(3): md(t, "key", "a"),
+		*TokenIDFromInt(2): md(t, "key", "b"),
+		*TokenIDFromInt(1): md(t, "key", "a"),
+		*TokenIDFromInt(0): md(t, "key", "a", "foo", "x"),
+	}
+
+	// Both TraitTypes have a {0.75, 0.25} distribution, considering nulls.
+	h := -0.75*math.Log2(0.75) - 0.25*math.Log2(0.25)
+	common := -math.Log2(0.75)
+	rare := -math.Log2(0.25)
+
+	ranked := func(idScoreRank ...float64) []RankedToken {
+		var r []RankedToken
+		for i := 0; i < len(idScoreRank); i += 3 {
+			r = append(r, RankedToken{
+				ID:    *TokenIDFromInt(int(idScoreRank[i])),
+				Score: idScoreRank[i+1],
+				Rank:  int(idScoreRank[i+2]),
+			})
+		}
+		return r
+	}
+
+	tests := []struct {
+		model RarityModel
+		want  []RankedToken
+	}{
+		{
+			model: InformationRarity{},
+			want: ranked(
+				0, (rare+common)/(2*h), 1,
+				2, (rare+common)/(2*h), 1,
+				1, 2*common/(2*h), 2,
+				3, 2*common/(2*h), 2,
+			),
+		},
+		{
+			model: TraitCountRarity{},
+			want: ranked(
+				0, 4, 1,
+				1, 4./3, 2,
+				2, 4./3, 2,
+				3, 4./3, 2,
+			),
+		},
+		{
+			model: StatisticalRarity{},
+			want: ranked(
+				0, 1/(0.75*0.25), 1,
+				2, 1/(0.75*0.25), 1,
+				1, 1/(0.75*0.75), 2,
+				3, 1/(0.75*0.75), 2,
+			),
+		},
+		{
+			model: AverageTraitRarity{},
+			want: ranked(
+				0, 2, 1,
+				2, 2, 1,
+				1, 4./3, 2,
+				3, 4./3, 2,
+			),
+		},
+		{
+			model: OpenRarity{},
+			want: ranked(
+				0, (2*rare+common)/(3*h), 1,
+				2, (rare+2*common)/(3*h), 2,
+				1, 3*common/(3*h), 3,
+				3, 3*common/(3*h), 3,
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%T", tt.model), func(t *testing.T) {
+			got := coll.RankRarity(tt.model, nil)
+			if diff := cmp.Diff(tt.want, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("%T.RankRarity(%T) diff (-want +got):\n%s", coll, tt.model, diff)
+			}
+		})
+	}
+}
+
+func TestInformationRarityMatchesCollectionRarity(t *testing.T) {
+	collections := [][]*Metadata{
+		{
+			md(t, "key", "0"),
+			md(t, "key", "0"),
+			md(t, "key", "2"),
+			md(t, "key", "3"),
+		},
+		{
+			md(t),
+			md(t, "foo", "bar"),
+			md(t, "key", "2", "foo", "bar"),
+			md(t, "key", "3", "foo", "baz"),
+			md(t, "key", "3", "hello", "world"),
+		},
+	}
+
+	for _, c := range collections {
+		coll := CollectionFromMetadata(c)
+		want := coll.Rarity(nil)
+
+		traits := coll.Traits(nil)
+		if got := traits.Entropy(); math.Abs(got-want.Entropy) > 1e-9 {
+			t.Errorf("%T.Entropy() got %f; want %f to match %T.Rarity().Entropy", traits, got, want.Entropy, coll)
+		}
+		got := InformationRarity{}.Scores(traits)
+		if diff := cmp.Diff(want.Scores, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+			t.Errorf("%T.Scores() diff from %T.Rarity().Scores (-want +got):\n%s", InformationRarity{}, coll, diff)
+		}
+	}
+}
+
+func TestTraits(t *testing.T) {
+	coll := Collection{
+		*TokenIDFromInt(0): md(t, "key", "a", "key", "b"),
+		*TokenIDFromInt(1): {Attributes: []*Attribute{{TraitType: "n", Value: 42}}},
+		*TokenIDFromInt(2): nil,
+	}
+
+	traits := coll.Traits(nil)
+	want := &Traits{
+		Size: 3,
+		Values: map[TokenID]map[string]string{
+			*TokenIDFromInt(0): {"key": "b"},
+			*TokenIDFromInt(1): {"n": "42"},
+			*TokenIDFromInt(2): {},
+		},
+		Counts: map[string]map[string]int{
+			"key": {"b": 1},
+			"n":   {"42": 1},
+		},
+	}
+	if diff := cmp.Diff(want, traits); diff != "" {
+		t.Errorf("%T.Traits(nil) diff (-want +got):\n%s", coll, diff)
+	}
+
+	if got, want := traits.NullCount("key"), 2; got != want {
+		t.Errorf("%T.NullCount(key) got %d; want %d", traits, got, want)
+	}
+	if got, want := traits.Count(*TokenIDFromInt(1), "key"), 2; got != want {
+		t.Errorf("%T.Count(1, key) got %d; want %d (null count)", traits, got, want)
+	}
+}
+
+func TestRankRarityDenseTies(t *testing.T) {
+	scores := map[TokenID]float64{
+		*TokenIDFromInt(5): 1,
+		*TokenIDFromInt(4): 3,
+		*TokenIDFromInt(3): 3,
+		*TokenIDFromInt(2): 2,
+		*TokenIDFromInt(1): 1,
+	}
+	want := []RankedToken{
+		{ID: *TokenIDFromInt(3), Score: 3, Rank: 1},
+		{ID: *TokenIDFromInt(4), Score: 3, Rank: 1},
+		{ID: *TokenIDFromInt(2), Score: 2, Rank: 2},
+		{ID: *TokenIDFromInt(1), Score: 1, Rank: 3},
+		{ID: *TokenIDFromInt(5), Score: 1, Rank: 3},
+	}
+	if diff := cmp.Diff(want, RankRarity(scores)); diff != "" {
+		t.Errorf("RankRarity(%v) diff (-want +got):\n%s", scores, diff)
+	}
+}
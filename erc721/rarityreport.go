@@ -0,0 +1,210 @@
+package erc721
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// A RarityReport explains rarity scores, including per-trait breakdowns and
+// the frequency of every trait value across the Collection.
+type RarityReport struct {
+	// Model is the name of the RarityModel used to compute scores. It is
+	// informational only.
+	Model string `json:"model,omitempty"`
+	// Entropy is Traits.Entropy(), in bits.
+	Entropy float64 `json:"entropy"`
+	// Tokens are ordered by rank, as returned by RankRarity().
+	Tokens []*TokenRarity `json:"tokens"`
+	// Traits are ordered by TraitType.
+	Traits []*TraitFrequencies `json:"traits"`
+}
+
+// A TokenRarity is a single token's rank, score and per-trait breakdown.
+type TokenRarity struct {
+	ID    TokenID `json:"id"`
+	Rank  int     `json:"rank"`
+	Score float64 `json:"score"`
+	// Traits includes every TraitType in the Collection, ordered by TraitType,
+	// including those that the token lacks, which are reported as null
+	// values.
+	Traits []*TraitContribution `json:"traits"`
+}
+
+// A TraitContribution describes the contribution of a single trait to a
+// token's rarity.
+type TraitContribution struct {
+	TraitType string `json:"trait_type"`
+	// Value is the, possibly bucketed, value of the trait. It is empty if Null
+	// is true, but an empty Value doesn't imply Null as the empty string is a
+	// valid value.
+	Value string `json:"value"`
+	// Null is true if the token lacks the TraitType.
+	Null bool `json:"null,omitempty"`
+	// Count is the number of tokens with the same Value, or lacking the
+	// TraitType if Null is true, and Frequency is Count as a proportion of the
+	// Collection.
+	Count     int     `json:"count"`
+	Frequency float64 `json:"frequency"`
+	// Information is the self-information, in bits, of the Value; i.e.
+	// -log2(Frequency). If the RarityReport was built from the Traits scored
+	// by InformationRarity or OpenRarity (see EffectiveTraits()), the sum of a
+	// token's Information, divided by the RarityReport's Entropy, is its
+	// score. Other models don't have such a breakdown, in which case the
+	// values are informational only.
+	Information float64 `json:"information"`
+}
+
+// TraitFrequencies is the frequency table of a single TraitType.
+type TraitFrequencies struct {
+	TraitType string `json:"trait_type"`
+	// Entropy, in bits, of the TraitType's distribution, including null
+	// values.
+	Entropy float64 `json:"entropy"`
+	// Values are ordered by decreasing Count and then by Value.
+	Values []*ValueFrequency `json:"values"`
+	// NullCount is the number of tokens lacking the TraitType, and
+	// NullFrequency is NullCount as a proportion of the Collection. This is
+	// the null value used by Collection.Rarity() and InformationRarity.
+	NullCount     int     `json:"null_count"`
+	NullFrequency float64 `json:"null_frequency"`
+}
+
+// A ValueFrequency is a single entry in a frequency table.
+type ValueFrequency struct {
+	Value     string  `json:"value"`
+	Count     int     `json:"count"`
+	Frequency float64 `json:"frequency"`
+}
+
+// NewRarityReport returns a RarityReport of the ranked tokens, which are
+// typically returned by RankRarity(model.Scores(traits)). The breakdown only
+// reflects the model's scores if t is EffectiveTraits(model, traits); e.g. it
+// then includes OpenRarity's meta trait.
+func NewRarityReport(t *Traits, ranked []RankedToken) *RarityReport {
+	n := float64(t.Size)
+	types := t.TraitTypes()
+
+	r := &RarityReport{
+		Entropy: t.Entropy(),
+	}
+
+	for _, rt := range ranked {
+		tr := &TokenRarity{
+			ID:    rt.ID,
+			Rank:  rt.Rank,
+			Score: rt.Score,
+		}
+		for _, tt := range types {
+			v, ok := t.Values[rt.ID][tt]
+			c := t.Count(rt.ID, tt)
+			tr.Traits = append(tr.Traits, &TraitContribution{
+				TraitType:   tt,
+				Value:       v,
+				Null:        !ok,
+				Count:       c,
+				Frequency:   float64(c) / n,
+				Information: -math.Log2(float64(c) / n),
+			})
+		}
+		r.Tokens = append(r.Tokens, tr)
+	}
+
+	for _, tt := range types {
+		tf := &TraitFrequencies{
+			TraitType: tt,
+			NullCount: t.NullCount(tt),
+		}
+		tf.NullFrequency = float64(tf.NullCount) / n
+		if tf.NullCount > 0 {
+			tf.Entropy = -tf.NullFrequency * math.Log2(tf.NullFrequency)
+		}
+
+		for v, c := range t.Counts[tt] {
+			tf.Values = append(tf.Values, &ValueFrequency{
+				Value:     v,
+				Count:     c,
+				Frequency: float64(c) / n,
+			})
+		}
+		sort.Slice(tf.Values, func(i, j int) bool {
+			vI, vJ := tf.Values[i], tf.Values[j]
+			if vI.Count != vJ.Count {
+				return vI.Count > vJ.Count
+			}
+			return vI.Value < vJ.Value
+		})
+		for _, v := range tf.Values {
+			tf.Entropy += -v.Frequency * math.Log2(v.Frequency)
+		}
+
+		r.Traits = append(r.Traits, tf)
+	}
+
+	return r
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *RarityReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("json.Encode(%T): %v", r, err)
+	}
+	return nil
+}
+
+// WriteTokensCSV writes one CSV row per token, in rank order, with columns:
+//
+//	rank, id, score, [<trait_type>, <trait_type>:frequency, <trait_type>:information]...
+//
+// for every TraitType, ordered as in r.Traits. Null values are written as
+// empty strings.
+func (r *RarityReport) WriteTokensCSV(w io.Writer) error {
+	header := []string{"rank", "id", "score"}
+	for _, tf := range r.Traits {
+		header = append(header, tf.TraitType, tf.TraitType+":frequency", tf.TraitType+":information")
+	}
+
+	rows := [][]string{header}
+	for _, tr := range r.Tokens {
+		row := []string{strconv.Itoa(tr.Rank), tr.ID.String(), formatFloat(tr.Score)}
+		for _, c := range tr.Traits {
+			row = append(row, c.Value, formatFloat(c.Frequency), formatFloat(c.Information))
+		}
+		rows = append(rows, row)
+	}
+	return writeCSV(w, rows)
+}
+
+// WriteTraitsCSV writes the frequency tables of all TraitTypes as CSV, with
+// columns:
+//
+//	trait_type, value, null, count, frequency
+//
+// A row with null = true is included for each TraitType that some tokens lack.
+func (r *RarityReport) WriteTraitsCSV(w io.Writer) error {
+	rows := [][]string{{"trait_type", "value", "null", "count", "frequency"}}
+	for _, tf := range r.Traits {
+		for _, v := range tf.Values {
+			rows = append(rows, []string{tf.TraitType, v.Value, "false", strconv.Itoa(v.Count), formatFloat(v.Frequency)})
+		}
+		if tf.NullCount > 0 {
+			rows = append(rows, []string{tf.TraitType, "", "true", strconv.Itoa(tf.NullCount), formatFloat(tf.NullFrequency)})
+		}
+	}
+	return writeCSV(w, rows)
+}
+
+// writeCSV writes all rows to w as CSV.
+func writeCSV(w io.Writer, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("%T.WriteAll(): %v", cw, err)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package erc721
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestRarityReport(t *testing.T) {
+	coll := Collection{
+		*TokenIDFromInt(0): md(t, "key", "a", "foo", "x"),
+		*TokenIDFromInt(1): md(t, "key", "a"),
+		*TokenIDFromInt(2): md(t, "key", "b"),
+		*TokenIDFromInt(3): md(t, "key", "a"),
+	}
+	traits := coll.Traits(nil)
+	ranked := RankRarity(InformationRarity{}.Scores(traits))
+	got := NewRarityReport(traits, ranked)
+
+	h := -0.75*math.Log2(0.75) - 0.25*math.Log2(0.25)
+	common := -math.Log2(0.75)
+	rare := -math.Log2(0.25)
+
+	contrib := func(traitType, value string, count int) *TraitContribution {
+		return &TraitContribution{
+			TraitType:   traitType,
+			Value:       value,
+			Null:        value == "",
+			Count:       count,
+			Frequency:   float64(count) / 4,
+			Information: -math.Log2(float64(count) / 4),
+		}
+	}
+
+	want := &RarityReport{
+		Entropy: 2 * h,
+		Tokens: []*TokenRarity{
+			{
+				ID:     *TokenIDFromInt(0),
+				Rank:   1,
+				Score:  (rare + common) / (2 * h),
+				Traits: []*TraitContribution{contrib("foo", "x", 1), contrib("key", "a", 3)},
+			},
+			{
+				ID:     *TokenIDFromInt(2),
+				Rank:   1,
+				Score:  (rare + common) / (2 * h),
+				Traits: []*TraitContribution{contrib("foo", "", 3), contrib("key", "b", 1)},
+			},
+			{
+				ID:     *TokenIDFromInt(1),
+				Rank:   2,
+				Score:  2 * common / (2 * h),
+				Traits: []*TraitContribution{contrib("foo", "", 3), contrib("key", "a", 3)},
+			},
+			{
+				ID:     *TokenIDFromInt(3),
+				Rank:   2,
+				Score:  2 * common / (2 * h),
+				Traits: []*TraitContribution{contrib("foo", "", 3), contrib("key", "a", 3)},
+			},
+		},
+		Traits: []*TraitFrequencies{
+			{
+				TraitType:     "foo",
+				Entropy:       h,
+				Values:        []*ValueFrequency{{Value: "x", Count: 1, Frequency: 0.25}},
+				NullCount:     3,
+				NullFrequency: 0.75,
+			},
+			{
+				TraitType: "key",
+				Entropy:   h,
+				Values: []*ValueFrequency{
+					{Value: "a", Count: 3, Frequency: 0.75},
+					{Value: "b", Count: 1, Frequency: 0.25},
+				},
+			},
+		},
+	}
+
+	approx := cmpopts.EquateApprox(0, 1e-9)
+	if diff := cmp.Diff(want, got, approx); diff != "" {
+		t.Errorf("NewRarityReport() diff (-want +got):\n%s", diff)
+	}
+
+	t.Run("information sums to score", func(t *testing.T) {
+		for _, tr := range got.Tokens {
+			var sum float64
+			for _, c := range tr.Traits {
+				sum += c.Information
+			}
+			if s := sum / got.Entropy; math.Abs(s-tr.Score) > 1e-9 {
+				t.Errorf("Token %s: sum of Information / Entropy = %f; want Score %f", &tr.ID, s, tr.Score)
+			}
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := got.WriteJSON(&buf); err != nil {
+			t.Fatalf("%T.WriteJSON() error %v", got, err)
+		}
+		rt := new(RarityReport)
+		if err := json.Unmarshal(buf.Bytes(), rt); err != nil {
+			t.Fatalf("json.Unmarshal(%T.WriteJSON()) error %v", got, err)
+		}
+		if diff := cmp.Diff(got, rt, approx); diff != "" {
+			t.Errorf("JSON round trip of %T diff (-want +got):\n%s", got, diff)
+		}
+	})
+
+	readCSV := func(t *testing.T, write func(*bytes.Buffer) error) [][]string {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := write(&buf); err != nil {
+			t.Fatalf("Writing CSV: %v", err)
+		}
+		rows, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("%T.ReadAll() error %v", csv.NewReader(nil), err)
+		}
+		return rows
+	}
+
+	t.Run("tokens CSV", func(t *testing.T) {
+		rows := readCSV(t, func(b *bytes.Buffer) error { return got.WriteTokensCSV(b) })
+
+		wantHeader := []string{
+			"rank", "id", "score",
+			"foo", "foo:frequency", "foo:information",
+			"key", "key:frequency", "key:information",
+		}
+		if diff := cmp.Diff(wantHeader, rows[0]); diff != "" {
+			t.Errorf("%T.WriteTokensCSV() header diff (-want +got):\n%s", got, diff)
+		}
+		wantRow := []string{
+			"1", "2", formatFloat(got.Tokens[1].Score),
+			"", "0.75", formatFloat(common),
+			"b", "0.25", "2",
+		}
+		if diff := cmp.Diff(wantRow, rows[2]); diff != "" {
+			t.Errorf("%T.WriteTokensCSV() second token diff (-want +got):\n%s", got, diff)
+		}
+		if n, want := len(rows), 5; n != want {
+			t.Errorf("%T.WriteTokensCSV() got %d rows; want %d", got, n, want)
+		}
+	})
+
+	t.Run("traits CSV", func(t *testing.T) {
+		rows := readCSV(t, func(b *bytes.Buffer) error { return got.WriteTraitsCSV(b) })
+
+		want := [][]string{
+			{"trait_type", "value", "null", "count", "frequency"},
+			{"foo", "x", "false", "1", "0.25"},
+			{"foo", "", "true", "3", "0.75"},
+			{"key", "a", "false", "3", "0.75"},
+			{"key", "b", "false", "1", "0.25"},
+		}
+		if diff := cmp.Diff(want, rows); diff != "" {
+			t.Errorf("%T.WriteTraitsCSV() diff (-want +got):\n%s", got, diff)
+		}
+	})
+}
+
+func TestRarityReportEffectiveTraits(t *testing.T) {
+	coll := Collection{
+		*TokenIDFromInt(0): md(t, "key", "a", "foo", "x"),
+		*TokenIDFromInt(1): md(t, "key", "a"),
+		*TokenIDFromInt(2): md(t, "key", "b"),
+		*TokenIDFromInt(3): md(t, "key", ""),
+	}
+	traits := coll.Traits(nil)
+
+	for _, model := range []RarityModel{InformationRarity{}, OpenRarity{}} {
+		r := NewRarityReport(EffectiveTraits(model, traits), RankRarity(model.Scores(traits)))
+
+		for _, tr := range r.Tokens {
+			var sum float64
+			for _, c := range tr.Traits {
+				sum += c.Information
+			}
+			if s := sum / r.Entropy; math.Abs(s-tr.Score) > 1e-9 {
+				t.Errorf("%T: token %s: sum of Information / Entropy = %f; want Score %f", model, &tr.ID, s, tr.Score)
+			}
+		}
+
+		if _, ok := model.(OpenRarity); !ok {
+			continue
+		}
+		var types []string
+		for _, tf := range r.Traits {
+			types = append(types, tf.TraitType)
+		}
+		if diff := cmp.Diff([]string{"foo", "key", OpenRarityTraitCountType}, types); diff != "" {
+			t.Errorf("NewRarityReport(EffectiveTraits(%T)) TraitTypes diff (-want +got):\n%s", model, diff)
+		}
+	}
+}
+
+func TestTraitContributionEmptyValueJSON(t *testing.T) {
+	for _, c := range []*TraitContribution{
+		{TraitType: "key", Value: ""},
+		{TraitType: "key", Null: true},
+	} {
+		buf, err := json.Marshal(c)
+		if err != nil {
+			t.Fatalf("json.Marshal(%+v) error %v", c, err)
+		}
+		if !bytes.Contains(buf, []byte(`"value":""`)) {
+			t.Errorf("json.Marshal(%+v) got %s; want explicit empty value", c, buf)
+		}
+	}
+}
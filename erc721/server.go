@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/golang/glog"
@@ -30,6 +31,13 @@ type Server struct {
 	// responding with metadata or images. Checks use the ownerOf function,
 	// which must not revert.
 	Contract Interface
+	// Mints, if provided, is used instead of Contract to confirm that tokens
+	// exist, removing RPC calls from the request path.
+	Mints *MintTracker
+	// MintedCountPath, if non-empty, is an HTTP path at which the number of
+	// minted tokens is served as JSON; e.g. {"minted":42}. It requires that
+	// Mints be non-nil.
+	MintedCountPath string
 
 	// Metadata and Image are responsible for returning a token's metadata and
 	// image, respectively (surprise, surprise!). If Contract is non-nil, the
@@ -39,6 +47,22 @@ type Server struct {
 	// they are selected based on their Path.
 	Metadata []MetadataEndpoint
 	Image    []ImageEndpoint
+	// RejectInvalidMetadata, if true, results in a 500 error instead of
+	// serving (or exporting) Metadata for which Validate() returns an error.
+	// All problems are logged.
+	RejectInvalidMetadata bool
+	// ContractMetadata, if non-nil, serves collection-level metadata for use
+	// as the contract's contractURI.
+	ContractMetadata *ContractMetadataEndpoint
+
+	// Cache, if non-nil, enables caching of token existence and memoisation of
+	// handler responses, as well as ETag and Cache-Control headers. It is read
+	// when s.Handler() is called and MUST NOT be modified thereafter.
+	Cache *CacheConfig
+
+	cache *serverCache
+	// now, if non-nil, overrides time.Now() as the cache's clock; for testing.
+	now func() time.Time
 }
 
 // A MetadataEndpoint specifies an HTTP path and associated handler for requests
@@ -56,6 +80,30 @@ type ImageEndpoint struct {
 	Handler ImageHandler
 }
 
+// A ContractMetadataEndpoint specifies an HTTP path and associated handler for
+// requests for ContractMetadata. If ImagePath is non-empty and the handler
+// returns ContractMetadata with an empty Image, it is replaced by the
+// Server's BaseURL with ImagePath as its path, in the same manner as for token
+// Metadata. If ImageHandler is also non-nil, it is used to serve requests to
+// ImagePath. As for token endpoints, handlers may only return 200, 400, 404 and
+// 500 codes; all others result in a 500.
+type ContractMetadataEndpoint struct {
+	Path    string
+	Handler ContractMetadataHandler
+
+	ImagePath    string
+	ImageHandler ContractImageHandler
+}
+
+type (
+	// A ContractMetadataHandler returns ContractMetadata for the ERC721
+	// instance that can be accessed via the Interface.
+	ContractMetadataHandler func(Interface) (md *ContractMetadata, httpCode int, err error)
+	// A ContractImageHandler is the image equivalent of a
+	// ContractMetadataHandler.
+	ContractImageHandler func(Interface) (img io.Reader, contentType string, httpCode int, err error)
+)
+
 type (
 	// A MetadataHandler returns Metadata for a specified TokenID, bound to an
 	// ERC721 instance that can be accessed via the Interface. It is typically
@@ -86,12 +134,31 @@ func (s *Server) Handler() (http.Handler, error) {
 		paths[fmt.Sprintf("Image[%d]", i)] = e.Path
 	}
 
+	if e := s.ContractMetadata; e != nil {
+		if e.Path == "" || e.Handler == nil {
+			return nil, fmt.Errorf("ContractMetadata must have non-empty Path and non-nil Handler")
+		}
+		if e.ImageHandler != nil && e.ImagePath == "" {
+			return nil, fmt.Errorf("ContractMetadata.ImageHandler requires non-empty ImagePath")
+		}
+	}
+
 	for name, path := range paths {
 		if !strings.Contains(path, fullTokenIDParam) {
 			return nil, fmt.Errorf("%s.Path %q must contain %q", name, path, fullTokenIDParam)
 		}
 	}
 
+	if s.MintedCountPath != "" && s.Mints == nil {
+		return nil, fmt.Errorf("MintedCountPath %q requires non-nil Mints", s.MintedCountPath)
+	}
+
+	now := s.now
+	if now == nil {
+		now = time.Now
+	}
+	s.cache = newServerCache(s.Cache, now)
+
 	r := httprouter.New()
 	for _, e := range s.Metadata {
 		r.GET(e.Path, s.metadata(e.Handler))
@@ -99,9 +166,26 @@ func (s *Server) Handler() (http.Handler, error) {
 	for _, e := range s.Image {
 		r.GET(e.Path, s.images(e.Handler))
 	}
+	if s.MintedCountPath != "" {
+		r.GET(s.MintedCountPath, s.mintedCount)
+	}
+	if e := s.ContractMetadata; e != nil {
+		r.GET(e.Path, s.contractMetadata(e))
+		if e.ImagePath != "" && e.ImageHandler != nil {
+			r.GET(e.ImagePath, s.contractImage(e.ImageHandler))
+		}
+	}
 	return r, nil
 }
 
+// mintedCount handles requests for the number of minted tokens.
+func (s *Server) mintedCount(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w.Header().Add("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Minted int `json:"minted"`
+	}{s.Mints.Count()})
+}
+
 // httpErrHandler allows httprouter.Handle-like functions to return errors. If
 // the returned error is of the type *httpError then its code is propagated; 400
 // and 404 errors also have their message propagated to the client. All other
@@ -153,6 +237,21 @@ func errorf(code int, format string, a ...interface{}) error {
 	}
 }
 
+// handlerCode returns nil if code is 200, and otherwise returns an httpError
+// carrying the code returned by the handler described by the format and args.
+// Only 400, 404 and 500 are propagated; all other codes result in a 500.
+func handlerCode(code int, format string, a ...interface{}) error {
+	desc := fmt.Sprintf(format, a...)
+	switch code {
+	case 200:
+		return nil
+	case 400, 404, 500:
+		return errorf(code, "%s returned code %d", desc, code)
+	default:
+		return errorf(500, "unsupported code %d returned by %s", code, desc)
+	}
+}
+
 // A tokenDataFunc returns arbitrary HTTP response data for a token.
 type tokenDataFunc func(Interface, *TokenID, httprouter.Params) (body io.Reader, contentType string, code int, err error)
 
@@ -171,24 +270,35 @@ func (s *Server) tokenDataHandler(w http.ResponseWriter, r *http.Request, params
 		return errorf(404, "token %q not minted", params.ByName(TokenIDParam))
 	}
 
+	key := responseKey(id, r)
+	if resp, ok := s.cache.response(key); ok {
+		return resp.write(w, r, s.Cache.CacheControl)
+	}
+
 	body, contentType, code, err := fn(s.Contract, id, params)
 	if err != nil {
 		return errorf(500, "%s(%s): %v", fnName, id, err)
 	}
 
-	switch code {
-	case 200:
-	case 400, 404, 500:
-		return errorf(code, "%s", err)
-	default:
-		return errorf(500, "unsupported code %d returned by %s(%s)", code, fnName, id)
+	if err := handlerCode(code, "%s(%s)", fnName, id); err != nil {
+		return err
 	}
 
-	w.Header().Add("Content-Type", contentType)
-	if _, err := io.Copy(w, body); err != nil {
-		return errorf(500, "io.Copy([http response], [%s data]): %v", fnName, err)
+	if s.Cache == nil {
+		w.Header().Add("Content-Type", contentType)
+		if _, err := io.Copy(w, body); err != nil {
+			return errorf(500, "io.Copy([http response], [%s data]): %v", fnName, err)
+		}
+		return nil
 	}
-	return nil
+
+	buf, err := io.ReadAll(body)
+	if err != nil {
+		return errorf(500, "io.ReadAll([%s data]): %v", fnName, err)
+	}
+	resp := newTokenDataResponse(buf, contentType)
+	s.cache.setResponse(key, resp)
+	return resp.write(w, r, s.Cache.CacheControl)
 }
 
 // metadata configures requests for metadata, sourcing it from the
@@ -202,11 +312,14 @@ func (s *Server) metadata(handler MetadataHandler) httprouter.Handle {
 				return nil, "", code, err
 			}
 
-			if md.Image == "" && len(s.Image) > 0 && md.AnimationURL == "" {
+			if md.Image == "" && md.ImageData == "" && len(s.Image) > 0 && md.AnimationURL == "" {
 				img := *s.BaseURL
-				img.Path = strings.ReplaceAll(s.Image[0].Path, fullTokenIDParam, id.Text(s.tokenIDBase()))
+				img.Path = s.tokenPath(s.Image[0].Path, id)
 				md.Image = img.String()
 			}
+			if err := s.validate(md); err != nil {
+				return nil, "", 500, err
+			}
 
 			buf, err := json.Marshal(md)
 			if err != nil {
@@ -218,6 +331,76 @@ func (s *Server) metadata(handler MetadataHandler) httprouter.Handle {
 	return httpErrHandler(h)
 }
 
+// validate returns md.Validate() if s.RejectInvalidMetadata is true, otherwise
+// it returns nil.
+func (s *Server) validate(md *Metadata) error {
+	if !s.RejectInvalidMetadata {
+		return nil
+	}
+	if err := md.Validate(); err != nil {
+		return fmt.Errorf("invalid metadata: %v", err)
+	}
+	return nil
+}
+
+// contractMetadata handles requests for ContractMetadata, substituting the
+// Image field as described by ContractMetadataEndpoint.
+func (s *Server) contractMetadata(e *ContractMetadataEndpoint) httprouter.Handle {
+	return httpErrHandler(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) error {
+		md, code, err := e.Handler(s.Contract)
+		if err != nil {
+			return errorf(500, "ContractMetadata(): %v", err)
+		}
+		if err := handlerCode(code, "ContractMetadata()"); err != nil {
+			return err
+		}
+
+		if md.Image == "" && e.ImagePath != "" {
+			img := *s.BaseURL
+			img.Path = e.ImagePath
+			md.Image = img.String()
+		}
+
+		buf, err := json.Marshal(md)
+		if err != nil {
+			return errorf(500, "json.Marshal(%T = %+v): %v", md, md, err)
+		}
+		return s.writeBuffered(w, r, buf, "application/json")
+	})
+}
+
+// contractImage handles requests for the collection-level image.
+func (s *Server) contractImage(handler ContractImageHandler) httprouter.Handle {
+	return httpErrHandler(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) error {
+		img, contentType, code, err := handler(s.Contract)
+		if err != nil {
+			return errorf(500, "ContractImage(): %v", err)
+		}
+		if err := handlerCode(code, "ContractImage()"); err != nil {
+			return err
+		}
+
+		buf, err := io.ReadAll(img)
+		if err != nil {
+			return errorf(500, "io.ReadAll([ContractImage data]): %v", err)
+		}
+		return s.writeBuffered(w, r, buf, contentType)
+	})
+}
+
+// writeBuffered writes the body as a response with the specified content type,
+// including caching headers if s.Cache is non-nil.
+func (s *Server) writeBuffered(w http.ResponseWriter, r *http.Request, body []byte, contentType string) error {
+	if s.Cache != nil {
+		return newTokenDataResponse(body, contentType).write(w, r, s.Cache.CacheControl)
+	}
+	w.Header().Add("Content-Type", contentType)
+	if _, err := w.Write(body); err != nil {
+		return errorf(500, "write response: %v", err)
+	}
+	return nil
+}
+
 // images handles requests for images, sourcing them from the user-provided
 // s.Images() function.
 func (s *Server) images(handler ImageHandler) httprouter.Handle {
@@ -236,8 +419,8 @@ const TokenIDParam = "tokenId"
 
 const fullTokenIDParam = ":" + TokenIDParam
 
-// tokenID extracts the `TokenIDParam` from the params. If s.Contract is
-// non-nil, it is used to check that the token already exists—if not then
+// tokenID extracts the `TokenIDParam` from the params. If s.Mints or s.Contract
+// is non-nil, it is used to check that the token already exists—if not then
 // tokenID() returns (nil, nil).
 func (s *Server) tokenID(params httprouter.Params) (*TokenID, error) {
 	rawID := params.ByName(TokenIDParam)
@@ -251,12 +434,45 @@ func (s *Server) tokenID(params httprouter.Params) (*TokenID, error) {
 		return nil, fmt.Errorf("token ID %q not parsed in base %d", rawID, base)
 	}
 
-	if s.Contract != nil {
-		if _, err := s.Contract.OwnerOf(nil, id); err != nil {
-			return nil, nil
-		}
+	tokenID, err := TokenIDFromBig(id)
+	if err != nil {
+		return nil, err
 	}
-	return TokenIDFromBig(id)
+	if !s.minted(tokenID) {
+		return nil, nil
+	}
+	return tokenID, nil
+}
+
+// minted reports whether the token exists, as determined by s.Mints or
+// s.Contract. If neither is set, all tokens are considered to exist.
+func (s *Server) minted(id *TokenID) bool {
+	if s.Mints != nil {
+		return s.Mints.Minted(id)
+	}
+	if s.Contract == nil {
+		return true
+	}
+
+	key := id.String()
+	minted, ok := s.cache.isMinted(key)
+	if !ok {
+		_, err := s.Contract.OwnerOf(nil, id.Big())
+		minted = err == nil
+		s.cache.setMinted(key, minted)
+	}
+	return minted
+}
+
+// tokenPath returns the path with the TokenIDParam replaced by the id,
+// formatted in s.tokenIDBase().
+func (s *Server) tokenPath(path string, id *TokenID) string {
+	return strings.ReplaceAll(path, fullTokenIDParam, s.tokenIDText(id))
+}
+
+// tokenIDText returns the id formatted in s.tokenIDBase().
+func (s *Server) tokenIDText(id *TokenID) string {
+	return id.Text(s.tokenIDBase())
 }
 
 // tokenIDBase returns s.TokenIDBase if non-zero, otherwise it returns 10.
@@ -419,3 +419,124 @@ func TestInternalImage(t *testing.T) {
 		})
 	}
 }
+
+func TestContractMetadata(t *testing.T) {
+	recipient := common.HexToAddress("0x71e059FA4594b69200541A189010188eDFFbC34D")
+
+	tests := []struct {
+		name      string
+		endpoint  *ContractMetadataEndpoint
+		wantImage func(baseURL string) string
+	}{
+		{
+			name: "internal image",
+			endpoint: &ContractMetadataEndpoint{
+				Path: "/contract",
+				Handler: func(Interface) (*ContractMetadata, int, error) {
+					return &ContractMetadata{
+						Name:                 "Collection",
+						SellerFeeBasisPoints: 500,
+						FeeRecipient:         &recipient,
+					}, 200, nil
+				},
+				ImagePath: "/contract/image",
+				ImageHandler: func(Interface) (io.Reader, string, int, error) {
+					return strings.NewReader("collection image"), "image/png", 200, nil
+				},
+			},
+			wantImage: func(baseURL string) string {
+				return baseURL + "/contract/image"
+			},
+		},
+		{
+			name: "explicit image",
+			endpoint: &ContractMetadataEndpoint{
+				Path: "/contract",
+				Handler: func(Interface) (*ContractMetadata, int, error) {
+					return &ContractMetadata{
+						Name:                 "Collection",
+						Image:                "ipfs://foo",
+						SellerFeeBasisPoints: 500,
+						FeeRecipient:         &recipient,
+					}, 200, nil
+				},
+				ImagePath: "/contract/image",
+			},
+			wantImage: func(string) string {
+				return "ipfs://foo"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &Server{ContractMetadata: tt.endpoint}
+			baseURL := start(t, srv)
+
+			url := baseURL + tt.endpoint.Path
+			resp := httpGet(t, url)
+			testContentType(t, resp, "application/json")
+
+			got := new(ContractMetadata)
+			if err := json.NewDecoder(resp.Body).Decode(got); err != nil {
+				t.Fatalf("json.Decode(HTTP GET %q) error %v", url, err)
+			}
+			want := &ContractMetadata{
+				Name:                 "Collection",
+				Image:                tt.wantImage(baseURL),
+				SellerFeeBasisPoints: 500,
+				FeeRecipient:         &recipient,
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("HTTP GET %q; parsed %T diff (-want +got):\n%s", url, got, diff)
+			}
+
+			if tt.endpoint.ImageHandler == nil {
+				return
+			}
+			resp = httpGet(t, got.Image)
+			testContentType(t, resp, "image/png")
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("io.ReadAll([http response body]): %v", err)
+			}
+			if want := "collection image"; string(body) != want {
+				t.Errorf("HTTP GET %q got body %q; want %q", got.Image, body, want)
+			}
+		})
+	}
+}
+
+func TestContractHandlerCodes(t *testing.T) {
+	for _, tt := range []struct {
+		code, want int
+	}{
+		{code: 400, want: 400},
+		{code: 404, want: 404},
+		{code: 500, want: 500},
+		{code: 302, want: 500},
+		{code: 418, want: 500},
+	} {
+		srv := &Server{
+			ContractMetadata: &ContractMetadataEndpoint{
+				Path: "/contract",
+				Handler: func(Interface) (*ContractMetadata, int, error) {
+					return nil, tt.code, nil
+				},
+				ImagePath: "/contract/image",
+				ImageHandler: func(Interface) (io.Reader, string, int, error) {
+					return nil, "", tt.code, nil
+				},
+			},
+		}
+		baseURL := start(t, srv)
+
+		for _, path := range []string{"/contract", "/contract/image"} {
+			resp := httpGet(t, baseURL+path)
+			resp.Body.Close()
+			if got := resp.StatusCode; got != tt.want {
+				t.Errorf("HTTP GET %q with handler returning code %d; got code %d; want %d", path, tt.code, got, tt.want)
+			}
+		}
+	}
+}
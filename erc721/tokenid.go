@@ -43,6 +43,31 @@ func (id *TokenID) Text(base int) string {
 	return id.Big().Text(base)
 }
 
+// MarshalText returns the decimal representation of id, allowing TokenIDs to
+// be used as JSON values and map keys.
+func (id TokenID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText parses a decimal, or 0x-prefixed hexadecimal, representation
+// of a TokenID.
+func (id *TokenID) UnmarshalText(buf []byte) error {
+	s, base := string(buf), 10
+	if len(s) > 2 && (s[:2] == "0x" || s[:2] == "0X") {
+		s, base = s[2:], 16
+	}
+	b, ok := new(big.Int).SetString(s, base)
+	if !ok || b.Sign() < 0 {
+		return fmt.Errorf("invalid %T %q", id, buf)
+	}
+	t, err := TokenIDFromBig(b)
+	if err != nil {
+		return err
+	}
+	*id = *t
+	return nil
+}
+
 // TokenIDFromUint256 returns the 32-byte buffer underlying u, typed as a
 // TokenID pointer.
 func TokenIDFromUint256(u *uint256.Int) *TokenID {
@@ -0,0 +1,81 @@
+package erc721
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+)
+
+func TestTokenIDText(t *testing.T) {
+	tests := []struct {
+		text           string
+		want           *TokenID
+		errDiffAgainst interface{}
+	}{
+		{
+			text: "42",
+			want: TokenIDFromInt(42),
+		},
+		{
+			text: "0x2a",
+			want: TokenIDFromInt(42),
+		},
+		{
+			// Leading zeros MUST NOT be interpreted as octal.
+			text: "010",
+			want: TokenIDFromInt(10),
+		},
+		{
+			text:           "-1",
+			errDiffAgainst: "invalid",
+		},
+		{
+			text:           "0xgg",
+			errDiffAgainst: "invalid",
+		},
+		{
+			text:           "",
+			errDiffAgainst: "invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := new(TokenID)
+			err := got.UnmarshalText([]byte(tt.text))
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Fatalf("%T.UnmarshalText(%q) %s", got, tt.text, diff)
+			}
+			if err != nil {
+				return
+			}
+			if got.Cmp(tt.want) != 0 {
+				t.Errorf("%T.UnmarshalText(%q) got %s; want %s", got, tt.text, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("JSON map keys", func(t *testing.T) {
+		in := map[TokenID]int{
+			*TokenIDFromInt(1):   1,
+			*TokenIDFromInt(256): 2,
+		}
+		buf, err := json.Marshal(in)
+		if err != nil {
+			t.Fatalf("json.Marshal(%T) error %v", in, err)
+		}
+		if got, want := string(buf), `{"1":1,"256":2}`; got != want {
+			t.Errorf("json.Marshal(%T) got %s; want %s", in, got, want)
+		}
+
+		var out map[TokenID]int
+		if err := json.Unmarshal(buf, &out); err != nil {
+			t.Fatalf("json.Unmarshal(%s, %T) error %v", buf, &out, err)
+		}
+		if diff := cmp.Diff(in, out); diff != "" {
+			t.Errorf("JSON round trip of %T diff (-want +got):\n%s", in, diff)
+		}
+	})
+}
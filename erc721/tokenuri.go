@@ -0,0 +1,245 @@
+package erc721
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// A URIFetcher returns the body and content type of the resource at an http(s)
+// URL.
+type URIFetcher func(ctx context.Context, url string) (body []byte, contentType string, err error)
+
+// DefaultIPFSGateway is the gateway used by a URIDecoder with an empty
+// IPFSGateway.
+const DefaultIPFSGateway = "https://ipfs.io/ipfs/"
+
+// A URIDecoder decodes tokenURIs, as returned by an ERC721 contract, into
+// Metadata and images. The zero value is ready to use.
+type URIDecoder struct {
+	// Fetch, if non-nil, is used to fetch http(s) URIs, including those
+	// rewritten to use the IPFSGateway. It defaults to an HTTP GET with
+	// http.DefaultClient, which treats non-2xx responses as errors.
+	Fetch URIFetcher
+	// IPFSGateway is the URL prefix to which the CID and path of ipfs:// URIs
+	// are appended; e.g. "https://ipfs.io/ipfs/". If empty,
+	// DefaultIPFSGateway is used.
+	IPFSGateway string
+}
+
+// A DecodedToken carries the Metadata and image of a token, as returned by
+// URIDecoder.Decode().
+type DecodedToken struct {
+	Metadata *Metadata
+	// Image and ImageContentType are empty if the Metadata has neither Image
+	// nor ImageData.
+	Image            []byte
+	ImageContentType string
+}
+
+// Decode resolves the tokenURI and parses it as Metadata, then resolves the
+// Metadata's Image, or ImageData if Image is empty. Supported URIs are data:
+// (base64 or percent-encoded), http(s):// and ipfs://.
+//
+// This allows, for example, tests to assert on the metadata rendered by a
+// fully on-chain contract, which typically returns a
+// data:application/json;base64 tokenURI with a data:image/svg+xml or
+// data:image/bmp image (see contracts/utils/BMP.sol).
+func (d *URIDecoder) Decode(ctx context.Context, tokenURI string) (*DecodedToken, error) {
+	md, err := d.Metadata(ctx, tokenURI)
+	if err != nil {
+		return nil, err
+	}
+	img, contentType, err := d.Image(ctx, md)
+	if err != nil {
+		return nil, err
+	}
+	return &DecodedToken{
+		Metadata:         md,
+		Image:            img,
+		ImageContentType: contentType,
+	}, nil
+}
+
+// Metadata resolves the tokenURI and parses it as Metadata, without resolving
+// the image.
+func (d *URIDecoder) Metadata(ctx context.Context, tokenURI string) (*Metadata, error) {
+	buf, _, err := d.Resolve(ctx, tokenURI)
+	if err != nil {
+		return nil, err
+	}
+	md := new(Metadata)
+	if err := json.Unmarshal(buf, md); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal([tokenURI contents], %T): %v", md, err)
+	}
+	return md, nil
+}
+
+// Image resolves md.Image, returning its contents and content type. If
+// md.Image is empty then md.ImageData is returned with content type
+// image/svg+xml, as OpenSea assumes SVG. If both are empty, Image returns
+// empty values and a nil error.
+func (d *URIDecoder) Image(ctx context.Context, md *Metadata) ([]byte, string, error) {
+	switch {
+	case md.Image != "":
+		buf, contentType, err := d.Resolve(ctx, md.Image)
+		if err != nil {
+			return nil, "", fmt.Errorf("image: %v", err)
+		}
+		return buf, contentType, nil
+	case md.ImageData != "":
+		return []byte(md.ImageData), "image/svg+xml", nil
+	default:
+		return nil, "", nil
+	}
+}
+
+// Resolve returns the contents and content type of the URI, which may be a
+// data:, http(s):// or ipfs:// URI.
+func (d *URIDecoder) Resolve(ctx context.Context, uri string) ([]byte, string, error) {
+	scheme, _, ok := strings.Cut(uri, ":")
+	if !ok {
+		return nil, "", fmt.Errorf("URI %q has no scheme", truncateURI(uri))
+	}
+
+	switch strings.ToLower(scheme) {
+	case "data":
+		return decodeDataURI(uri)
+	case "http", "https":
+		return d.fetch(ctx, uri)
+	case "ipfs":
+		u, err := d.gatewayURL(uri)
+		if err != nil {
+			return nil, "", err
+		}
+		return d.fetch(ctx, u)
+	default:
+		return nil, "", fmt.Errorf("unsupported URI scheme %q", scheme)
+	}
+}
+
+// gatewayURL returns the ipfs:// URI rewritten to use the IPFS gateway. Both
+// ipfs://<cid>/<path> and the legacy ipfs://ipfs/<cid>/<path> are supported.
+func (d *URIDecoder) gatewayURL(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("url.Parse(%q): %v", uri, err)
+	}
+	p := strings.TrimPrefix(u.Host+u.Path, "ipfs/")
+	if p == "" {
+		return "", fmt.Errorf("IPFS URI %q has no CID", uri)
+	}
+
+	gw := d.IPFSGateway
+	if gw == "" {
+		gw = DefaultIPFSGateway
+	}
+	if !strings.HasSuffix(gw, "/") {
+		gw += "/"
+	}
+
+	out := gw + p
+	if u.RawQuery != "" {
+		out += "?" + u.RawQuery
+	}
+	return out, nil
+}
+
+// fetch returns d.Fetch(ctx, url), or the default equivalent if d.Fetch is
+// nil.
+func (d *URIDecoder) fetch(ctx context.Context, url string) ([]byte, string, error) {
+	f := d.Fetch
+	if f == nil {
+		f = httpFetch
+	}
+	buf, contentType, err := f(ctx, url)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetch %q: %v", url, err)
+	}
+	return buf, contentType, nil
+}
+
+// httpFetch is the default URIFetcher.
+func httpFetch(ctx context.Context, url string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("http.NewRequest(GET, %q): %v", url, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, "", fmt.Errorf("HTTP status %s", resp.Status)
+	}
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("io.ReadAll([response body]): %v", err)
+	}
+	return buf, resp.Header.Get("Content-Type"), nil
+}
+
+// decodeDataURI returns the data and media type of an RFC 2397 data URI. If the
+// data are not base64 encoded, they are percent-decoded; as contracts commonly
+// concatenate unescaped JSON or SVG, data that aren't valid percent-encoding
+// are returned verbatim.
+func decodeDataURI(uri string) ([]byte, string, error) {
+	meta, data, ok := strings.Cut(strings.TrimPrefix(uri[len("data"):], ":"), ",")
+	if !ok {
+		return nil, "", fmt.Errorf("data URI %q missing comma", truncateURI(uri))
+	}
+
+	params := strings.Split(meta, ";")
+	isBase64 := params[len(params)-1] == "base64"
+	if isBase64 {
+		params = params[:len(params)-1]
+	}
+
+	mediaType := strings.Join(params, ";")
+	if mediaType == "" || strings.HasPrefix(mediaType, ";") {
+		mediaType = "text/plain" + mediaType
+	}
+	if _, _, err := mime.ParseMediaType(mediaType); err != nil {
+		// Non-standard parameters such as ;utf8 are common, so only the
+		// type itself is required to be valid.
+		mt, _, _ := strings.Cut(mediaType, ";")
+		if _, _, err := mime.ParseMediaType(mt); err != nil {
+			return nil, "", fmt.Errorf("data URI media type %q: %v", mediaType, err)
+		}
+	}
+
+	if !isBase64 {
+		if s, err := url.PathUnescape(data); err == nil {
+			return []byte(s), mediaType, nil
+		}
+		return []byte(data), mediaType, nil
+	}
+
+	buf, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		var rawErr error
+		buf, rawErr = base64.RawStdEncoding.DecodeString(data)
+		if rawErr != nil {
+			return nil, "", fmt.Errorf("base64 decoding of data URI: %v", err)
+		}
+	}
+	return buf, mediaType, nil
+}
+
+// truncateURI returns the URI truncated for use in error messages, as data
+// URIs can be very long.
+func truncateURI(uri string) string {
+	const max = 64
+	if len(uri) <= max {
+		return uri
+	}
+	return uri[:max] + "..."
+}
@@ -0,0 +1,238 @@
+package erc721
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+	"golang.org/x/image/bmp"
+)
+
+func base64DataURI(mediaType string, data []byte) string {
+	return fmt.Sprintf("data:%s;base64,%s", mediaType, base64.StdEncoding.EncodeToString(data))
+}
+
+func TestURIDecoder(t *testing.T) {
+	ctx := context.Background()
+
+	const svg = `<svg xmlns="http://www.w3.org/2000/svg"><rect fill="#ff0000"/></svg>`
+
+	bmpImg := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	bmpImg.Set(1, 0, color.RGBA{R: 0xff, A: 0xff})
+	var bmpBuf bytes.Buffer
+	if err := bmp.Encode(&bmpBuf, bmpImg); err != nil {
+		t.Fatalf("bmp.Encode() error %v", err)
+	}
+
+	// remote is served by the fake fetcher, keyed by URL.
+	remote := map[string]struct {
+		body, contentType string
+	}{
+		"https://example.com/1":                 {`{"name":"Remote","image":"ipfs://bafyimg/1.png"}`, "application/json"},
+		"https://gw.example/ipfs/bafyimg/1.png": {"png bytes", "image/png"},
+		"https://gw.example/ipfs/bafymd/2":      {`{"name":"IPFS"}`, "application/json"},
+	}
+	var fetched []string
+	fetch := func(_ context.Context, url string) ([]byte, string, error) {
+		fetched = append(fetched, url)
+		r, ok := remote[url]
+		if !ok {
+			return nil, "", errors.New("not found")
+		}
+		return []byte(r.body), r.contentType, nil
+	}
+
+	tests := []struct {
+		name        string
+		tokenURI    string
+		want        *DecodedToken
+		wantFetched []string
+	}{
+		{
+			name: "base64 JSON with base64 SVG",
+			tokenURI: base64DataURI("application/json", []byte(fmt.Sprintf(
+				`{"name":"On-chain","image":%q,"attributes":[{"trait_type":"Colour","value":"Red"}]}`,
+				base64DataURI("image/svg+xml", []byte(svg)),
+			))),
+			want: &DecodedToken{
+				Metadata: &Metadata{
+					Name:       "On-chain",
+					Image:      base64DataURI("image/svg+xml", []byte(svg)),
+					Attributes: []*Attribute{{TraitType: "Colour", Value: "Red"}},
+				},
+				Image:            []byte(svg),
+				ImageContentType: "image/svg+xml",
+			},
+		},
+		{
+			name: "base64 JSON with BMP",
+			tokenURI: base64DataURI("application/json", []byte(fmt.Sprintf(
+				`{"name":"Pixels","image":%q}`,
+				base64DataURI("image/bmp", bmpBuf.Bytes()),
+			))),
+			want: &DecodedToken{
+				Metadata: &Metadata{
+					Name:  "Pixels",
+					Image: base64DataURI("image/bmp", bmpBuf.Bytes()),
+				},
+				Image:            bmpBuf.Bytes(),
+				ImageContentType: "image/bmp",
+			},
+		},
+		{
+			name:     "unescaped JSON with image_data",
+			tokenURI: `data:application/json;utf8,{"name":"100% on-chain","image_data":"<svg/>"}`,
+			want: &DecodedToken{
+				Metadata: &Metadata{
+					Name:      "100% on-chain",
+					ImageData: "<svg/>",
+				},
+				Image:            []byte("<svg/>"),
+				ImageContentType: "image/svg+xml",
+			},
+		},
+		{
+			name:     "percent-encoded JSON without image",
+			tokenURI: `data:application/json,%7B%22name%22%3A%22Escaped%22%7D`,
+			want: &DecodedToken{
+				Metadata: &Metadata{Name: "Escaped"},
+			},
+		},
+		{
+			name:     "HTTPS with IPFS image",
+			tokenURI: "https://example.com/1",
+			want: &DecodedToken{
+				Metadata: &Metadata{
+					Name:  "Remote",
+					Image: "ipfs://bafyimg/1.png",
+				},
+				Image:            []byte("png bytes"),
+				ImageContentType: "image/png",
+			},
+			wantFetched: []string{
+				"https://example.com/1",
+				"https://gw.example/ipfs/bafyimg/1.png",
+			},
+		},
+		{
+			name:     "legacy IPFS URI",
+			tokenURI: "ipfs://ipfs/bafymd/2",
+			want: &DecodedToken{
+				Metadata: &Metadata{Name: "IPFS"},
+			},
+			wantFetched: []string{"https://gw.example/ipfs/bafymd/2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched = nil
+			d := &URIDecoder{
+				Fetch:       fetch,
+				IPFSGateway: "https://gw.example/ipfs",
+			}
+
+			got, err := d.Decode(ctx, tt.tokenURI)
+			if err != nil {
+				t.Fatalf("%T.Decode(%q) error %v", d, tt.tokenURI, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("%T.Decode(%q) diff (-want +got):\n%s", d, tt.tokenURI, diff)
+			}
+			if diff := cmp.Diff(tt.wantFetched, fetched); diff != "" {
+				t.Errorf("%T.Decode(%q) fetched URLs diff (-want +got):\n%s", d, tt.tokenURI, diff)
+			}
+		})
+	}
+
+	t.Run("decoded BMP", func(t *testing.T) {
+		d := new(URIDecoder)
+		tokenURI := base64DataURI("application/json", []byte(fmt.Sprintf(`{"image":%q}`, base64DataURI("image/bmp", bmpBuf.Bytes()))))
+		got, err := d.Decode(ctx, tokenURI)
+		if err != nil {
+			t.Fatalf("%T.Decode() error %v", d, err)
+		}
+		img, err := bmp.Decode(bytes.NewReader(got.Image))
+		if err != nil {
+			t.Fatalf("bmp.Decode(%T.Decode().Image) error %v", d, err)
+		}
+		if r, _, _, _ := img.At(1, 0).RGBA(); r != 0xffff {
+			t.Errorf("Decoded BMP pixel (1,0) red = %#x; want 0xffff", r)
+		}
+	})
+}
+
+func TestURIDecoderErrors(t *testing.T) {
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		name           string
+		tokenURI       string
+		errDiffAgainst interface{}
+	}{
+		{
+			name:           "no scheme",
+			tokenURI:       "foo",
+			errDiffAgainst: "no scheme",
+		},
+		{
+			name:           "unsupported scheme",
+			tokenURI:       "ftp://example.com",
+			errDiffAgainst: `unsupported URI scheme "ftp"`,
+		},
+		{
+			name:           "data URI without comma",
+			tokenURI:       "data:application/json;base64",
+			errDiffAgainst: "missing comma",
+		},
+		{
+			name:           "invalid base64",
+			tokenURI:       "data:application/json;base64,!!!",
+			errDiffAgainst: "base64",
+		},
+		{
+			name:           "invalid JSON",
+			tokenURI:       "data:application/json,{",
+			errDiffAgainst: "json.Unmarshal",
+		},
+		{
+			name:           "unresolvable image",
+			tokenURI:       `data:application/json,{"image":"nope"}`,
+			errDiffAgainst: "image: URI",
+		},
+		{
+			name:           "IPFS URI without CID",
+			tokenURI:       "ipfs://",
+			errDiffAgainst: "no CID",
+		},
+		{
+			name:           "HTTP error",
+			tokenURI:       srv.URL,
+			errDiffAgainst: "404",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := new(URIDecoder)
+			_, err := d.Decode(ctx, tt.tokenURI)
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Errorf("%T.Decode(%q) %s", d, tt.tokenURI, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package erc721
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// A ValidationError describes a single problem found by Metadata.Validate() or
+// Collection.Validate().
+type ValidationError struct {
+	// TokenID is nil unless the error was returned by Collection.Validate().
+	TokenID *TokenID
+	// Field is the path of the invalid field, using JSON names; e.g.
+	// "image" or "attributes[2].value". It is empty if the problem relates to
+	// the Metadata as a whole.
+	Field string
+	Msg   string
+}
+
+// Error returns the error message, prefixed by the token ID and field path, if
+// they are set.
+func (e *ValidationError) Error() string {
+	var parts []string
+	if e.TokenID != nil {
+		parts = append(parts, fmt.Sprintf("token %s", e.TokenID))
+	}
+	if e.Field != "" {
+		parts = append(parts, e.Field)
+	}
+	return strings.Join(append(parts, e.Msg), ": ")
+}
+
+// ValidationErrors is a set of ValidationError problems, returned as an error
+// by Metadata.Validate() and Collection.Validate().
+type ValidationErrors []*ValidationError
+
+// Error returns the messages of all errors, separated by semicolons.
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validate checks the Metadata against the ERC721 metadata schema and the
+// OpenSea metadata standards, returning all problems as ValidationErrors, or
+// nil if the Metadata is valid. Checks include:
+//
+//   - URL fields (image, animation_url, external_url, youtube_url) being
+//     absolute URIs;
+//   - BackgroundColor being a six-character hex colour;
+//   - Attributes being non-nil, with a supported DisplayType;
+//   - Attribute values being strings, booleans or numbers;
+//   - Numerical display types (number, boost_number, boost_percentage) having
+//     numerical values;
+//   - Attribute max_value being a number, not exceeded by the value; and
+//   - DisplayDate values being non-negative, integral Unix timestamps, in
+//     seconds.
+func (md *Metadata) Validate() error {
+	if errs := md.validate(); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Validate calls Validate() on all Metadata in the Collection, returning all
+// problems as ValidationErrors, ordered by TokenID, or nil if all Metadata are
+// valid.
+func (coll Collection) Validate() error {
+	ids := make([]TokenID, 0, len(coll))
+	for id := range coll {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].Cmp(&ids[j]) < 0
+	})
+
+	var all ValidationErrors
+	for _, id := range ids {
+		id := id
+		for _, err := range coll[id].validate() {
+			err.TokenID = &id
+			all = append(all, err)
+		}
+	}
+	if len(all) > 0 {
+		return all
+	}
+	return nil
+}
+
+// validate returns all problems with the Metadata, without TokenIDs.
+func (md *Metadata) validate() ValidationErrors {
+	if md == nil {
+		return ValidationErrors{{Msg: "nil Metadata"}}
+	}
+
+	var errs ValidationErrors
+	errorf := func(field, format string, a ...interface{}) {
+		errs = append(errs, &ValidationError{
+			Field: field,
+			Msg:   fmt.Sprintf(format, a...),
+		})
+	}
+
+	for _, u := range []struct {
+		field, val string
+	}{
+		{"image", md.Image},
+		{"animation_url", md.AnimationURL},
+		{"external_url", md.ExternalURL},
+		{"youtube_url", md.YouTubeURL},
+	} {
+		if u.val == "" {
+			continue
+		}
+		if err := validateURI(u.val); err != nil {
+			errorf(u.field, "%v", err)
+		}
+	}
+
+	if c := md.BackgroundColor; c != "" && !isHexColor(c) {
+		errorf("background_color", "%q is not a six-character hex colour without leading #", c)
+	}
+
+	for i, a := range md.Attributes {
+		field := fmt.Sprintf("attributes[%d]", i)
+		if a == nil {
+			errorf(field, "nil %T", a)
+			continue
+		}
+
+		if a.DisplayType < DisplayDefault || a.DisplayType >= endDisplayTypes {
+			errorf(field+".display_type", "unsupported %T = %d", a.DisplayType, a.DisplayType)
+			continue
+		}
+
+		if a.MaxValue != nil {
+			max, ok := numericValue(a.MaxValue)
+			if !ok {
+				errorf(field+".max_value", "requires a number; got %T %v", a.MaxValue, a.MaxValue)
+			} else if v, ok := numericValue(a.Value); ok && v > max {
+				errorf(field+".value", "%v exceeds max_value %v", a.Value, a.MaxValue)
+			}
+		}
+
+		field += ".value"
+		switch a.Value.(type) {
+		case nil:
+			errorf(field, "missing value")
+			continue
+		case string, bool:
+		default:
+			if _, ok := numericValue(a.Value); !ok {
+				errorf(field, "unsupported type %T; must be string, bool or number", a.Value)
+				continue
+			}
+		}
+
+		switch a.DisplayType {
+		case DisplayNumber, DisplayBoostNumber, DisplayBoostPercentage:
+			if _, ok := numericValue(a.Value); !ok {
+				errorf(field, "display_type %q requires a number; got %T %v", a.DisplayType, a.Value, a.Value)
+			}
+		case DisplayDate:
+			if err := validateTimestamp(a.Value); err != nil {
+				errorf(field, "display_type %q: %v", a.DisplayType, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateURI returns an error if s is not an absolute URI. Schemes that
+// require a host (http, https, ipfs and ar) are checked for one.
+func validateURI(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid URI: %v", err)
+	}
+	if u.Scheme == "" {
+		return fmt.Errorf("URI %q has no scheme", s)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "ipfs", "ar":
+		if u.Host == "" {
+			return fmt.Errorf("URI %q has no host", s)
+		}
+	}
+	return nil
+}
+
+// isHexColor reports whether s is a six-character hexadecimal colour.
+func isHexColor(s string) bool {
+	if len(s) != 6 {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// maxUnixTimestamp is the last second of the year 9999. Larger DisplayDate
+// values are almost certainly a result of using milliseconds.
+const maxUnixTimestamp = 253402300799
+
+// validateTimestamp returns an error if v is not a non-negative, integral Unix
+// timestamp in seconds.
+func validateTimestamp(v interface{}) error {
+	f, ok := numericValue(v)
+	switch {
+	case !ok:
+		return fmt.Errorf("requires a Unix timestamp; got %T %v", v, v)
+	case f < 0 || f != math.Trunc(f):
+		return fmt.Errorf("requires a non-negative, integral Unix timestamp; got %v", v)
+	case f > maxUnixTimestamp:
+		return fmt.Errorf("timestamp %v is after year 9999; milliseconds used instead of seconds?", v)
+	}
+	return nil
+}
+
+// numericValue returns v as a float64, and true, if v is a numerical type,
+// including json.Number. Otherwise it returns false.
+func numericValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
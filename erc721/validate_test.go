@@ -0,0 +1,221 @@
+package erc721
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/julienschmidt/httprouter"
+)
+
+// validationProblems returns the Field and Msg of all ValidationErrors in err,
+// formatted as strings.
+func validationProblems(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("got error of type %T; want %T", err, ValidationErrors{})
+	}
+
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Error())
+	}
+	return got
+}
+
+func TestMetadataValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		md   *Metadata
+		// json, if non-empty, is unmarshalled into a new Metadata in place
+		// of md, to test values as they are decoded.
+		json string
+		want []string
+	}{
+		{
+			name: "nil",
+			want: []string{"nil Metadata"},
+		},
+		{
+			name: "valid",
+			md: &Metadata{
+				Name:         "Token",
+				Image:        "ipfs://bafybeiczsscdsbs7ffqz55asqdf3smv6klcw3gofszvwlyarci47bgf354/0.png",
+				AnimationURL: "https://example.com/0.mp4",
+				ExternalURL:  "https://example.com",
+				Attributes: []*Attribute{
+					{TraitType: "Hat", Value: "Cap"},
+					{TraitType: "Shiny", Value: true},
+					{TraitType: "Level", Value: 3, DisplayType: DisplayNumber},
+					{TraitType: "Boost", Value: 1.5, DisplayType: DisplayBoostPercentage},
+					{TraitType: "Birthday", Value: uint64(1546360800), DisplayType: DisplayDate},
+				},
+			},
+		},
+		{
+			name: "valid from JSON",
+			json: `{"image":"data:image/svg+xml;base64,PHN2Zy8+","attributes":[{"trait_type":"Level","value":3,"display_type":"number"},{"trait_type":"Birthday","value":1546360800,"display_type":"date"}]}`,
+		},
+		{
+			name: "invalid URIs",
+			md: &Metadata{
+				Image:        "not a uri",
+				AnimationURL: "https:///no-host",
+				ExternalURL:  "ipfs://",
+			},
+			want: []string{
+				`image: URI "not a uri" has no scheme`,
+				`animation_url: URI "https:///no-host" has no host`,
+				`external_url: URI "ipfs://" has no host`,
+			},
+		},
+		{
+			name: "invalid attributes",
+			md: &Metadata{
+				Attributes: []*Attribute{
+					{TraitType: "OK", Value: "ok"},
+					nil,
+					{TraitType: "Missing"},
+					{TraitType: "Slice", Value: []string{"a"}},
+					{TraitType: "Level", Value: "3", DisplayType: DisplayNumber},
+					{TraitType: "Boost", Value: true, DisplayType: DisplayBoostNumber},
+					{TraitType: "Bad type", Value: 1, DisplayType: endDisplayTypes},
+				},
+			},
+			want: []string{
+				"attributes[1]: nil *erc721.Attribute",
+				"attributes[2].value: missing value",
+				"attributes[3].value: unsupported type []string; must be string, bool or number",
+				`attributes[4].value: display_type "number" requires a number; got string 3`,
+				`attributes[5].value: display_type "boost_number" requires a number; got bool true`,
+				"attributes[6].display_type: unsupported erc721.OpenSeaDisplayType = 5",
+			},
+		},
+		{
+			name: "invalid extended fields",
+			md: &Metadata{
+				BackgroundColor: "#ffffff",
+				YouTubeURL:      "youtube",
+				Attributes: []*Attribute{
+					{TraitType: "OK", Value: 3, MaxValue: 3, DisplayType: DisplayNumber},
+					{TraitType: "Over", Value: 4, MaxValue: 3, DisplayType: DisplayNumber},
+					{TraitType: "Max", Value: 1, MaxValue: "three", DisplayType: DisplayNumber},
+				},
+			},
+			want: []string{
+				`youtube_url: URI "youtube" has no scheme`,
+				`background_color: "#ffffff" is not a six-character hex colour without leading #`,
+				"attributes[1].value: 4 exceeds max_value 3",
+				"attributes[2].max_value: requires a number; got string three",
+			},
+		},
+		{
+			name: "invalid dates",
+			md: &Metadata{
+				Attributes: []*Attribute{
+					{TraitType: "String", Value: "2019-01-01", DisplayType: DisplayDate},
+					{TraitType: "Negative", Value: -1, DisplayType: DisplayDate},
+					{TraitType: "Fractional", Value: 1.5, DisplayType: DisplayDate},
+					{TraitType: "Millis", Value: int64(1546360800000), DisplayType: DisplayDate},
+				},
+			},
+			want: []string{
+				`attributes[0].value: display_type "date": requires a Unix timestamp; got string 2019-01-01`,
+				`attributes[1].value: display_type "date": requires a non-negative, integral Unix timestamp; got -1`,
+				`attributes[2].value: display_type "date": requires a non-negative, integral Unix timestamp; got 1.5`,
+				`attributes[3].value: display_type "date": timestamp 1546360800000 is after year 9999; milliseconds used instead of seconds?`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := tt.md
+			if tt.json != "" {
+				md = new(Metadata)
+				if err := json.Unmarshal([]byte(tt.json), md); err != nil {
+					t.Fatalf("json.Unmarshal(%s) error %v", tt.json, err)
+				}
+			}
+
+			got := validationProblems(t, md.Validate())
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("%T.Validate() problems diff (-want +got):\n%s", md, diff)
+			}
+		})
+	}
+}
+
+func TestCollectionValidate(t *testing.T) {
+	valid := &Metadata{Name: "valid"}
+	coll := Collection{
+		*TokenIDFromInt(10): {Image: "x"},
+		*TokenIDFromInt(0):  valid,
+		*TokenIDFromInt(2): {
+			Image:      "y",
+			Attributes: []*Attribute{{TraitType: "n", Value: "x", DisplayType: DisplayNumber}},
+		},
+		*TokenIDFromInt(3): nil,
+	}
+
+	want := []string{
+		`token 2: image: URI "y" has no scheme`,
+		`token 2: attributes[0].value: display_type "number" requires a number; got string x`,
+		"token 3: nil Metadata",
+		`token 10: image: URI "x" has no scheme`,
+	}
+	if diff := cmp.Diff(want, validationProblems(t, coll.Validate())); diff != "" {
+		t.Errorf("%T.Validate() problems diff (-want +got):\n%s", coll, diff)
+	}
+
+	if err := (Collection{*TokenIDFromInt(0): valid}).Validate(); err != nil {
+		t.Errorf("%T{valid}.Validate() got error %v; want nil", coll, err)
+	}
+}
+
+func TestServerRejectInvalidMetadata(t *testing.T) {
+	for _, reject := range []bool{false, true} {
+		t.Run(fmt.Sprintf("reject=%t", reject), func(t *testing.T) {
+			srv := &Server{
+				RejectInvalidMetadata: reject,
+				Metadata: []MetadataEndpoint{{
+					Path: "/metadata/:tokenId",
+					Handler: func(_ Interface, id *TokenID, _ httprouter.Params) (*Metadata, int, error) {
+						md := &Metadata{Name: fmt.Sprintf("Token %s", id)}
+						if id.Cmp(TokenIDFromInt(1)) == 0 {
+							md.Attributes = []*Attribute{{TraitType: "n", Value: "x", DisplayType: DisplayNumber}}
+						}
+						return md, 200, nil
+					},
+				}},
+			}
+			baseURL := start(t, srv)
+
+			for id, want := range map[int]int{
+				0: 200,
+				1: map[bool]int{false: 200, true: 500}[reject],
+			} {
+				url := fmt.Sprintf("%s/metadata/%d", baseURL, id)
+				resp := httpGet(t, url)
+				resp.Body.Close()
+				if got := resp.StatusCode; got != want {
+					t.Errorf("HTTP GET %q got code %d; want %d", url, got, want)
+				}
+			}
+
+			_, err := srv.Export(ExportConfig{
+				Dir:  t.TempDir(),
+				From: TokenIDFromInt(0),
+				To:   TokenIDFromInt(1),
+			})
+			if gotErr := err != nil; gotErr != reject {
+				t.Errorf("%T{RejectInvalidMetadata: %t}.Export() got error %v; want error %t", srv, reject, err, reject)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package erc721
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// A logWatcher passes all logs matching a filter query to a handler, until
+// closed or the underlying subscription fails.
+type logWatcher struct {
+	sub  ethereum.Subscription
+	done chan struct{}
+
+	errMu sync.Mutex
+	err   error
+}
+
+// watchLogs subscribes to logs matching the query and then, if fromBlock is
+// non-nil, backfills all matching logs since fromBlock. All logs are passed to
+// handle, which is only ever called from a single goroutine at a time.
+// watchLogs returns once the backfill is complete.
+func watchLogs(ctx context.Context, filterer bind.ContractFilterer, q ethereum.FilterQuery, fromBlock *uint64, handle func(types.Log)) (*logWatcher, error) {
+	// Subscribing before backfilling guarantees that there is no gap between
+	// the two. Handlers MUST therefore be idempotent as logs may overlap.
+	logs := make(chan types.Log, 64)
+	sub, err := filterer.SubscribeFilterLogs(ctx, q, logs)
+	if err != nil {
+		return nil, fmt.Errorf("%T.SubscribeFilterLogs(%+v): %v", filterer, q, err)
+	}
+
+	if fromBlock != nil {
+		q.FromBlock = new(big.Int).SetUint64(*fromBlock)
+		past, err := filterer.FilterLogs(ctx, q)
+		if err != nil {
+			sub.Unsubscribe()
+			return nil, fmt.Errorf("%T.FilterLogs(%+v): %v", filterer, q, err)
+		}
+		for _, l := range past {
+			handle(l)
+		}
+	}
+
+	w := &logWatcher{
+		sub:  sub,
+		done: make(chan struct{}),
+	}
+	go w.watch(logs, handle)
+	return w, nil
+}
+
+// watch passes logs from the subscription to handle until it ends.
+func (w *logWatcher) watch(logs <-chan types.Log, handle func(types.Log)) {
+	defer close(w.done)
+	for {
+		select {
+		case l := <-logs:
+			handle(l)
+		case err := <-w.sub.Err():
+			w.errMu.Lock()
+			w.err = err
+			w.errMu.Unlock()
+			return
+		}
+	}
+}
+
+// Err returns the error that ended the subscription, if any. Once the
+// subscription has ended, no further events are processed.
+func (w *logWatcher) Err() error {
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+	return w.err
+}
+
+// Close unsubscribes from events and waits for the subscription to end.
+func (w *logWatcher) Close() {
+	w.sub.Unsubscribe()
+	<-w.done
+}
+
+// Topics of the ERC4906 events, as emitted by contracts/erc721/ERC4906.sol.
+var (
+	MetadataUpdateEventTopic      = crypto.Keccak256Hash([]byte("MetadataUpdate(uint256)"))
+	BatchMetadataUpdateEventTopic = crypto.Keccak256Hash([]byte("BatchMetadataUpdate(uint256,uint256)"))
+)
+
+// A MetadataUpdate describes an ERC4906 MetadataUpdate or BatchMetadataUpdate
+// event. The range of affected tokens, [From, To], is inclusive; From and To
+// are equal for a MetadataUpdate event.
+type MetadataUpdate struct {
+	From, To *TokenID
+	// Log is the raw log from which the MetadataUpdate was parsed. If
+	// Log.Removed is true then the event was reverted by a chain
+	// reorganisation; this is typically of no consequence as metadata
+	// typically reflects the current state of the contract, which has changed
+	// either way.
+	Log types.Log
+}
+
+// Contains reports whether id is in the range of tokens affected by the
+// update.
+func (u MetadataUpdate) Contains(id *TokenID) bool {
+	return id.Cmp(u.From) >= 0 && id.Cmp(u.To) <= 0
+}
+
+// A MetadataUpdateHandler is called for every MetadataUpdate observed by a
+// MetadataUpdateWatcher. See Server.InvalidateMetadata() for a handler that
+// removes stale responses from a Server's cache.
+type MetadataUpdateHandler func(MetadataUpdate)
+
+// A MetadataUpdateWatcher watches a contract for ERC4906 events.
+type MetadataUpdateWatcher struct {
+	*logWatcher
+}
+
+// NewMetadataUpdateWatcher subscribes to the contract's ERC4906 events and
+// calls every handler, in order, for each one. Handlers are called
+// sequentially from a single goroutine. The context is only used for the
+// initial subscription, which remains active until Close() is called or it
+// fails (see Err()).
+func NewMetadataUpdateWatcher(ctx context.Context, filterer bind.ContractFilterer, contract common.Address, handlers ...MetadataUpdateHandler) (*MetadataUpdateWatcher, error) {
+	q := ethereum.FilterQuery{
+		Addresses: []common.Address{contract},
+		Topics: [][]common.Hash{
+			{MetadataUpdateEventTopic, BatchMetadataUpdateEventTopic},
+		},
+	}
+
+	w, err := watchLogs(ctx, filterer, q, nil, func(l types.Log) {
+		u, ok := parseMetadataUpdate(l)
+		if !ok {
+			return
+		}
+		for _, h := range handlers {
+			h(u)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MetadataUpdateWatcher{w}, nil
+}
+
+// parseMetadataUpdate parses the log as an ERC4906 event, returning false if it
+// is not one. Event parameters aren't indexed so are in the log's data.
+func parseMetadataUpdate(l types.Log) (MetadataUpdate, bool) {
+	if len(l.Topics) != 1 {
+		return MetadataUpdate{}, false
+	}
+
+	u := MetadataUpdate{Log: l}
+	switch t := l.Topics[0]; {
+	case t == MetadataUpdateEventTopic && len(l.Data) == 32:
+		id := TokenID(common.BytesToHash(l.Data))
+		u.From, u.To = &id, &id
+	case t == BatchMetadataUpdateEventTopic && len(l.Data) == 64:
+		from := TokenID(common.BytesToHash(l.Data[:32]))
+		to := TokenID(common.BytesToHash(l.Data[32:]))
+		u.From, u.To = &from, &to
+	default:
+		return MetadataUpdate{}, false
+	}
+	return u, true
+}
@@ -0,0 +1,227 @@
+package erc721
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestParseMetadataUpdate(t *testing.T) {
+	// data returns the concatenation of 32-byte words.
+	data := func(words ...int64) []byte {
+		var buf []byte
+		for _, w := range words {
+			buf = append(buf, common.BigToHash(big.NewInt(w)).Bytes()...)
+		}
+		return buf
+	}
+
+	tests := []struct {
+		name   string
+		log    types.Log
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "MetadataUpdate",
+			log:    types.Log{Topics: []common.Hash{MetadataUpdateEventTopic}, Data: data(42)},
+			want:   "[42,42]",
+			wantOK: true,
+		},
+		{
+			name:   "BatchMetadataUpdate",
+			log:    types.Log{Topics: []common.Hash{BatchMetadataUpdateEventTopic}, Data: data(10, 20)},
+			want:   "[10,20]",
+			wantOK: true,
+		},
+		{
+			name: "malformed MetadataUpdate",
+			log:  types.Log{Topics: []common.Hash{MetadataUpdateEventTopic}, Data: data(1, 2)},
+		},
+		{
+			name: "malformed BatchMetadataUpdate",
+			log:  types.Log{Topics: []common.Hash{BatchMetadataUpdateEventTopic}, Data: data(7)},
+		},
+		{
+			name: "indexed parameter",
+			log:  types.Log{Topics: []common.Hash{MetadataUpdateEventTopic, common.BigToHash(big.NewInt(42))}},
+		},
+		{
+			name: "other event",
+			log:  types.Log{Topics: []common.Hash{TransferEventTopic}, Data: data(1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, ok := parseMetadataUpdate(tt.log)
+			if ok != tt.wantOK {
+				t.Fatalf("parseMetadataUpdate(%+v) got ok = %t; want %t", tt.log, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got := fmt.Sprintf("[%s,%s]", u.From, u.To); got != tt.want {
+				t.Errorf("parseMetadataUpdate(%+v) got %s; want %s", tt.log, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataUpdateWatcher(t *testing.T) {
+	ctx := context.Background()
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	addr, nft := deployTestable(t, sim)
+
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+	hook := func(u MetadataUpdate) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, fmt.Sprintf("[%s,%s]", u.From, u.To))
+	}
+
+	w, err := NewMetadataUpdateWatcher(ctx, sim, addr, hook)
+	if err != nil {
+		t.Fatalf("NewMetadataUpdateWatcher() error %v", err)
+	}
+	t.Cleanup(w.Close)
+
+	// Transfer events from minting are ignored.
+	sim.Must(t, "%T.MintN(5)", nft)(nft.MintN(sim.Acc(deployer), big.NewInt(5)))
+	sim.Must(t, "%T.EmitMetadataUpdateForAll()", nft)(nft.EmitMetadataUpdateForAll(sim.Acc(steerer)))
+	sim.Must(t, "%T.MintN(2)", nft)(nft.MintN(sim.Acc(deployer), big.NewInt(2)))
+	sim.Must(t, "%T.EmitMetadataUpdateForAll()", nft)(nft.EmitMetadataUpdateForAll(sim.Acc(steerer)))
+
+	want := []string{"[0,5]", "[0,7]"}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(got)
+		mu.Unlock()
+		if n >= len(want) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("%T hook received updates diff (-want +got):\n%s", w, diff)
+	}
+}
+
+func TestMetadataUpdateContains(t *testing.T) {
+	u := MetadataUpdate{From: TokenIDFromInt(10), To: TokenIDFromInt(20)}
+	for id, want := range map[int]bool{
+		9:  false,
+		10: true,
+		15: true,
+		20: true,
+		21: false,
+	} {
+		if got := u.Contains(TokenIDFromInt(id)); got != want {
+			t.Errorf("%T{10,20}.Contains(%d) got %t; want %t", u, id, got, want)
+		}
+	}
+}
+
+func TestServerInvalidateMetadata(t *testing.T) {
+	ctx := context.Background()
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	addr, nft := deployTestable(t, sim)
+	sim.Must(t, "%T.MintN(3)", nft)(nft.MintN(sim.Acc(deployer), big.NewInt(3)))
+
+	var (
+		mu      sync.Mutex
+		version = "v1"
+	)
+	srv := &Server{
+		Metadata: []MetadataEndpoint{{
+			Path: "/metadata/:tokenId",
+			Handler: func(_ Interface, id *TokenID, _ httprouter.Params) (*Metadata, int, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				return &Metadata{Name: fmt.Sprintf("Token %s %s", id, version)}, 200, nil
+			},
+		}},
+		Cache: &CacheConfig{MaxResponses: 100},
+	}
+	baseURL := start(t, srv)
+
+	updates := make(chan MetadataUpdate, 10)
+	w, err := NewMetadataUpdateWatcher(ctx, sim, addr, srv.InvalidateMetadata, func(u MetadataUpdate) {
+		updates <- u
+	})
+	if err != nil {
+		t.Fatalf("NewMetadataUpdateWatcher() error %v", err)
+	}
+	t.Cleanup(w.Close)
+
+	wantNames := func(t *testing.T, want map[int]string) {
+		t.Helper()
+		for id, wantName := range want {
+			got := metadataFromResponse(t, httpGet(t, fmt.Sprintf("%s/metadata/%d", baseURL, id)))
+			if got.Name != wantName {
+				t.Errorf("Token %d; got name %q; want %q", id, got.Name, wantName)
+			}
+		}
+	}
+	emitAndAwait := func(t *testing.T) {
+		t.Helper()
+		sim.Must(t, "%T.EmitMetadataUpdateForAll()", nft)(nft.EmitMetadataUpdateForAll(sim.Acc(steerer)))
+		select {
+		case <-updates:
+		case <-time.After(5 * time.Second):
+			t.Fatal("MetadataUpdate not received")
+		}
+	}
+	setVersion := func(v string) {
+		mu.Lock()
+		defer mu.Unlock()
+		version = v
+	}
+
+	wantNames(t, map[int]string{
+		0:  "Token 0 v1",
+		2:  "Token 2 v1",
+		10: "Token 10 v1",
+	})
+
+	// Memoised responses are stale until invalidated.
+	setVersion("v2")
+	wantNames(t, map[int]string{
+		0:  "Token 0 v1",
+		2:  "Token 2 v1",
+		10: "Token 10 v1",
+	})
+
+	// The update covers [0, totalSupply()] so token 10 remains stale.
+	emitAndAwait(t)
+	wantNames(t, map[int]string{
+		0:  "Token 0 v2",
+		2:  "Token 2 v2",
+		10: "Token 10 v1",
+	})
+
+	setVersion("v3")
+	sim.Must(t, "%T.MintN(7)", nft)(nft.MintN(sim.Acc(deployer), big.NewInt(7)))
+	emitAndAwait(t)
+	wantNames(t, map[int]string{
+		0:  "Token 0 v3",
+		2:  "Token 2 v3",
+		10: "Token 10 v3",
+	})
+}
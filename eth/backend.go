@@ -0,0 +1,65 @@
+package eth
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// A KeyBackend holds, or has access to, the private key of a single account and
+// signs on its behalf. It allows a Signer to be used without the private key
+// being held in memory.
+type KeyBackend interface {
+	// Address returns the address of the account.
+	Address() common.Address
+	// SignHash returns an ECDSA signature of the 32-byte hash, in the
+	// [R || S || V] format, with V being 0 or 1, as returned by go-ethereum's
+	// crypto.Sign().
+	SignHash(hash []byte) ([]byte, error)
+}
+
+// A TextSigner is a KeyBackend that signs EIP-191 personal messages itself,
+// without exposing the ability to sign arbitrary hashes. If a Signer's
+// KeyBackend is also a TextSigner, it is used for all personal signatures.
+type TextSigner interface {
+	KeyBackend
+	// SignText returns an ECDSA signature of
+	// keccak256(WithPersonalMessagePrefix(msg)), in the same format as
+	// KeyBackend.SignHash(), except that V MAY be 27 or 28.
+	SignText(msg []byte) ([]byte, error)
+}
+
+// NewSignerFromBackend returns a Signer that delegates all signing to the
+// backend.
+func NewSignerFromBackend(b KeyBackend) *Signer {
+	return &Signer{backend: b}
+}
+
+// NewSignerFromPrivateKey returns a Signer backed by the private key, held in
+// memory.
+func NewSignerFromPrivateKey(key *ecdsa.PrivateKey) *Signer {
+	return NewSignerFromBackend(privateKeyBackend{key})
+}
+
+// privateKeyBackend is a KeyBackend that holds the private key in memory.
+type privateKeyBackend struct {
+	key *ecdsa.PrivateKey
+}
+
+var _ KeyBackend = privateKeyBackend{}
+
+// Address returns the address derived from the public key.
+func (b privateKeyBackend) Address() common.Address {
+	return crypto.PubkeyToAddress(b.key.PublicKey)
+}
+
+// SignHash returns crypto.Sign(hash, <key>).
+func (b privateKeyBackend) SignHash(hash []byte) ([]byte, error) {
+	sig, err := crypto.Sign(hash, b.key)
+	if err != nil {
+		return nil, fmt.Errorf("crypto.Sign(): %v", err)
+	}
+	return sig, nil
+}
@@ -0,0 +1,118 @@
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/h-fam/errdiff"
+)
+
+func TestSignerFromKeystoreFile(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("crypto.GenerateKey() error %v", err)
+	}
+
+	const passphrase = "correct horse battery staple"
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	acc, err := ks.ImportECDSA(priv, passphrase)
+	if err != nil {
+		t.Fatalf("%T.ImportECDSA() error %v", ks, err)
+	}
+	path := acc.URL.Path
+
+	t.Run("correct passphrase", func(t *testing.T) {
+		s, err := SignerFromKeystoreFile(path, passphrase)
+		if err != nil {
+			t.Fatalf("SignerFromKeystoreFile() error %v", err)
+		}
+		if got, want := s.Address(), acc.Address; got != want {
+			t.Errorf("SignerFromKeystoreFile().Address() got %v; want %v", got, want)
+		}
+		if got := s.Mnemonic(); got != "" {
+			t.Errorf("SignerFromKeystoreFile().Mnemonic() got %q; want empty string", got)
+		}
+	})
+
+	t.Run("incorrect passphrase", func(t *testing.T) {
+		_, err := SignerFromKeystoreFile(path, "wrong")
+		if diff := errdiff.Substring(err, "decrypt keystore key"); diff != "" {
+			t.Errorf("SignerFromKeystoreFile() with incorrect passphrase; %s", diff)
+		}
+	})
+}
+
+// clefStub mimics the account_signData method of Clef's external API.
+type clefStub struct {
+	key *ecdsa.PrivateKey
+}
+
+func (c *clefStub) SignData(_ context.Context, contentType string, _ common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	sig, err := crypto.Sign(accounts.TextHash(data), c.key)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27 // as with Clef
+	return sig, nil
+}
+
+func TestRemoteBackend(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("crypto.GenerateKey() error %v", err)
+	}
+	want := crypto.PubkeyToAddress(priv.PublicKey)
+
+	srv := rpc.NewServer()
+	t.Cleanup(srv.Stop)
+	if err := srv.RegisterName("account", &clefStub{priv}); err != nil {
+		t.Fatalf("%T.RegisterName() error %v", srv, err)
+	}
+	client := rpc.DialInProc(srv)
+	t.Cleanup(client.Close)
+
+	s := NewSignerFromBackend(NewRemoteBackend(client, want))
+
+	t.Run("personal", func(t *testing.T) {
+		msg := []byte("hello")
+		sig, err := s.PersonalSign(msg)
+		if err != nil {
+			t.Fatalf("%T.PersonalSign() error %v", s, err)
+		}
+		got, err := RecoverPersonal(msg, sig)
+		if err != nil {
+			t.Fatalf("RecoverPersonal() error %v", err)
+		}
+		if got != want {
+			t.Errorf("RecoverPersonal(%T.PersonalSign()) got %v; want %v", s, got, want)
+		}
+	})
+
+	t.Run("voucher with nonce", func(t *testing.T) {
+		v, err := s.SignVoucherWithNonce(want, uint8(1))
+		if err != nil {
+			t.Fatalf("%T.SignVoucherWithNonce() error %v", s, err)
+		}
+		got, err := v.Signer()
+		if err != nil {
+			t.Fatalf("%T.Signer() error %v", v, err)
+		}
+		if got != want {
+			t.Errorf("%T.Signer() got %v; want %v", v, got, want)
+		}
+	})
+
+	t.Run("hash unsupported", func(t *testing.T) {
+		_, err := s.Sign([]byte("hello"))
+		if diff := errdiff.Substring(err, "only supports EIP-191 personal signatures"); diff != "" {
+			t.Errorf("%T.Sign() with %T; %s", s, s.backend, diff)
+		}
+	})
+}
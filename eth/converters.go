@@ -1,7 +1,9 @@
 package eth
 
 import (
+	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/params"
 )
@@ -16,3 +18,161 @@ func EtherFraction(numerator, denominator int64) *big.Int {
 func Ether(e int64) *big.Int {
 	return EtherFraction(e, 1)
 }
+
+// Decimals of common ETH denominations, for use with ParseUnits() and
+// FormatUnits().
+const (
+	WeiDecimals   = 0
+	GweiDecimals  = 9
+	EtherDecimals = 18
+)
+
+// unitDecimals maps from (lower-case) unit names accepted by ParseWithUnit() to
+// their decimals.
+var unitDecimals = map[string]uint8{
+	"wei":   WeiDecimals,
+	"gwei":  GweiDecimals,
+	"ether": EtherDecimals,
+	"eth":   EtherDecimals,
+}
+
+// ParseUnits parses the decimal string s, scaled up by 10^decimals; e.g.
+// ParseUnits("0.0825", 18) returns 0.0825 ETH in Wei. The result is exact and
+// an error is returned if s has more significant fractional digits than
+// decimals, as they would otherwise be lost. Trailing fractional zeros are
+// permitted, as are a leading minus sign and omission of the integer or
+// fractional part (but not both). Exponents and digit separators are not
+// supported.
+func ParseUnits(s string, decimals uint8) (*big.Int, error) {
+	in := s
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i != -1 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	if intPart == "" && fracPart == "" {
+		return nil, fmt.Errorf("parse %q: no digits", in)
+	}
+	for _, part := range []string{intPart, fracPart} {
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return nil, fmt.Errorf("parse %q: invalid character %q", in, r)
+			}
+		}
+	}
+
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > int(decimals) {
+		return nil, fmt.Errorf("parse %q: %d significant fractional digits exceeds %d decimals; precision would be lost", in, len(fracPart), decimals)
+	}
+	fracPart += strings.Repeat("0", int(decimals)-len(fracPart))
+
+	digits := strings.TrimLeft(intPart+fracPart, "0")
+	if digits == "" {
+		return new(big.Int), nil
+	}
+	x, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("parse %q: invalid decimal", in)
+	}
+	if neg {
+		x.Neg(x)
+	}
+	return x, nil
+}
+
+// ParseEther is equivalent to ParseUnits(s, EtherDecimals).
+func ParseEther(s string) (*big.Int, error) {
+	return ParseUnits(s, EtherDecimals)
+}
+
+// ParseGwei is equivalent to ParseUnits(s, GweiDecimals).
+func ParseGwei(s string) (*big.Int, error) {
+	return ParseUnits(s, GweiDecimals)
+}
+
+// ParseWithUnit parses a decimal string followed by whitespace and a unit, one
+// of wei, gwei, or ether (alternatively eth), case insensitive; e.g. "0.0825
+// ether" or "30 gwei". The returned value is in Wei. See ParseUnits() for
+// details of the accepted decimal format.
+func ParseWithUnit(s string) (*big.Int, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("parse %q: want <decimal> <unit>", s)
+	}
+	decimals, ok := unitDecimals[strings.ToLower(fields[1])]
+	if !ok {
+		return nil, fmt.Errorf("parse %q: unsupported unit %q", s, fields[1])
+	}
+	return ParseUnits(fields[0], decimals)
+}
+
+// FormatUnits returns x scaled down by 10^decimals as an exact decimal string,
+// without trailing fractional zeros; e.g. FormatUnits(<0.0825 ETH in Wei>, 18)
+// returns "0.0825". It is the inverse of ParseUnits().
+func FormatUnits(x *big.Int, decimals uint8) string {
+	intPart, fracPart := splitUnits(x, decimals)
+	if fracPart = strings.TrimRight(fracPart, "0"); fracPart == "" {
+		return intPart
+	}
+	return intPart + "." + fracPart
+}
+
+// FormatUnitsFixed is equivalent to FormatUnits() except that the fractional
+// part has exactly precision digits, padded with zeros or rounded half away
+// from zero as necessary. A precision of zero results in no decimal point.
+func FormatUnitsFixed(x *big.Int, decimals, precision uint8) string {
+	if precision < decimals {
+		// Round half away from zero by adding (or subtracting, for negative
+		// values) half of the truncated magnitude before truncating.
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals-precision)), nil)
+		half := new(big.Int).Rsh(scale, 1)
+		if x.Sign() == -1 {
+			half.Neg(half)
+		}
+		r := new(big.Int).Add(x, half)
+		r.Quo(r, scale) // Quo truncates towards zero
+		intPart, fracPart := splitUnits(r, precision)
+		if precision == 0 {
+			return intPart
+		}
+		return intPart + "." + fracPart
+	}
+
+	intPart, fracPart := splitUnits(x, decimals)
+	fracPart += strings.Repeat("0", int(precision-decimals))
+	if precision == 0 {
+		return intPart
+	}
+	return intPart + "." + fracPart
+}
+
+// FormatEther is equivalent to FormatUnits(x, EtherDecimals).
+func FormatEther(x *big.Int) string {
+	return FormatUnits(x, EtherDecimals)
+}
+
+// FormatGwei is equivalent to FormatUnits(x, GweiDecimals).
+func FormatGwei(x *big.Int) string {
+	return FormatUnits(x, GweiDecimals)
+}
+
+// splitUnits returns the integer and fractional parts of x/10^decimals, the
+// latter being exactly decimals digits long. A negative sign, if any, is
+// included in the integer part.
+func splitUnits(x *big.Int, decimals uint8) (string, string) {
+	digits := new(big.Int).Abs(x).String()
+	if n := int(decimals) + 1; len(digits) < n {
+		digits = strings.Repeat("0", n-len(digits)) + digits
+	}
+	split := len(digits) - int(decimals)
+	intPart, fracPart := digits[:split], digits[split:]
+	if x.Sign() == -1 {
+		intPart = "-" + intPart
+	}
+	return intPart, fracPart
+}
@@ -0,0 +1,144 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/h-fam/errdiff"
+)
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		in             string
+		decimals       uint8
+		want           *big.Int
+		errDiffAgainst interface{}
+	}{
+		{in: "0.0825", decimals: EtherDecimals, want: EtherFraction(825, 10000)},
+		{in: "1", decimals: EtherDecimals, want: Ether(1)},
+		{in: "42.", decimals: EtherDecimals, want: Ether(42)},
+		{in: ".5", decimals: EtherDecimals, want: EtherFraction(1, 2)},
+		{in: "-1.5", decimals: EtherDecimals, want: EtherFraction(-3, 2)},
+		{in: "30", decimals: GweiDecimals, want: big.NewInt(30e9)},
+		{in: "1.000000000", decimals: GweiDecimals, want: big.NewInt(1e9)},
+		{in: "0.000000001", decimals: GweiDecimals, want: big.NewInt(1)},
+		{in: "12.34", decimals: 6, want: big.NewInt(12340000)},
+		{in: "007", decimals: WeiDecimals, want: big.NewInt(7)},
+		{in: "0", decimals: EtherDecimals, want: big.NewInt(0)},
+		{in: "-0.0", decimals: EtherDecimals, want: big.NewInt(0)},
+		{in: "0.0000000001", decimals: GweiDecimals, errDiffAgainst: "precision would be lost"},
+		{in: "1.5", decimals: WeiDecimals, errDiffAgainst: "precision would be lost"},
+		{in: "", decimals: EtherDecimals, errDiffAgainst: "no digits"},
+		{in: ".", decimals: EtherDecimals, errDiffAgainst: "no digits"},
+		{in: "-", decimals: EtherDecimals, errDiffAgainst: "no digits"},
+		{in: "1e18", decimals: EtherDecimals, errDiffAgainst: "invalid character"},
+		{in: "1,000", decimals: EtherDecimals, errDiffAgainst: "invalid character"},
+		{in: "1.2.3", decimals: EtherDecimals, errDiffAgainst: "invalid character"},
+		{in: "--1", decimals: EtherDecimals, errDiffAgainst: "invalid character"},
+		{in: " 1", decimals: EtherDecimals, errDiffAgainst: "invalid character"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseUnits(tt.in, tt.decimals)
+		if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+			t.Errorf("ParseUnits(%q, %d) %s", tt.in, tt.decimals, diff)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got.Cmp(tt.want) != 0 {
+			t.Errorf("ParseUnits(%q, %d) got %d; want %d", tt.in, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestParseWithUnit(t *testing.T) {
+	tests := []struct {
+		in             string
+		want           *big.Int
+		errDiffAgainst interface{}
+	}{
+		{in: "0.0825 ether", want: EtherFraction(825, 10000)},
+		{in: "0.0825 ETH", want: EtherFraction(825, 10000)},
+		{in: "  30\tGwei ", want: big.NewInt(30e9)},
+		{in: "1 wei", want: big.NewInt(1)},
+		{in: "1.5 wei", errDiffAgainst: "precision would be lost"},
+		{in: "1 finney", errDiffAgainst: "unsupported unit"},
+		{in: "1", errDiffAgainst: "want <decimal> <unit>"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseWithUnit(tt.in)
+		if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+			t.Errorf("ParseWithUnit(%q) %s", tt.in, diff)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got.Cmp(tt.want) != 0 {
+			t.Errorf("ParseWithUnit(%q) got %d; want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		x        *big.Int
+		decimals uint8
+		want     string
+	}{
+		{x: EtherFraction(825, 10000), decimals: EtherDecimals, want: "0.0825"},
+		{x: Ether(42), decimals: EtherDecimals, want: "42"},
+		{x: EtherFraction(-3, 2), decimals: EtherDecimals, want: "-1.5"},
+		{x: big.NewInt(1), decimals: EtherDecimals, want: "0.000000000000000001"},
+		{x: big.NewInt(0), decimals: EtherDecimals, want: "0"},
+		{x: big.NewInt(30e9), decimals: GweiDecimals, want: "30"},
+		{x: big.NewInt(12345), decimals: WeiDecimals, want: "12345"},
+		{x: big.NewInt(-12345), decimals: 2, want: "-123.45"},
+	}
+
+	for _, tt := range tests {
+		got := FormatUnits(tt.x, tt.decimals)
+		if got != tt.want {
+			t.Errorf("FormatUnits(%d, %d) got %q; want %q", tt.x, tt.decimals, got, tt.want)
+		}
+
+		back, err := ParseUnits(got, tt.decimals)
+		if err != nil {
+			t.Errorf("ParseUnits(FormatUnits(%d, %d)) error %v", tt.x, tt.decimals, err)
+			continue
+		}
+		if back.Cmp(tt.x) != 0 {
+			t.Errorf("ParseUnits(FormatUnits(%d, %d)) got %d; want round trip", tt.x, tt.decimals, back)
+		}
+	}
+}
+
+func TestFormatUnitsFixed(t *testing.T) {
+	tests := []struct {
+		x                   *big.Int
+		decimals, precision uint8
+		want                string
+	}{
+		{x: EtherFraction(825, 10000), decimals: EtherDecimals, precision: 4, want: "0.0825"},
+		{x: EtherFraction(825, 10000), decimals: EtherDecimals, precision: 6, want: "0.082500"},
+		{x: EtherFraction(825, 10000), decimals: EtherDecimals, precision: 3, want: "0.083"},
+		{x: EtherFraction(825, 10000), decimals: EtherDecimals, precision: 2, want: "0.08"},
+		{x: EtherFraction(-825, 10000), decimals: EtherDecimals, precision: 3, want: "-0.083"},
+		{x: EtherFraction(3, 2), decimals: EtherDecimals, precision: 0, want: "2"},
+		{x: EtherFraction(-3, 2), decimals: EtherDecimals, precision: 0, want: "-2"},
+		{x: EtherFraction(7, 5), decimals: EtherDecimals, precision: 0, want: "1"},
+		{x: Ether(1), decimals: EtherDecimals, precision: 2, want: "1.00"},
+		{x: big.NewInt(12345), decimals: 2, precision: 4, want: "123.4500"},
+		{x: big.NewInt(12345), decimals: WeiDecimals, precision: 0, want: "12345"},
+		{x: big.NewInt(995), decimals: 3, precision: 2, want: "1.00"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatUnitsFixed(tt.x, tt.decimals, tt.precision); got != tt.want {
+			t.Errorf("FormatUnitsFixed(%d, %d, %d) got %q; want %q", tt.x, tt.decimals, tt.precision, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package eth
+
+import (
+	"fmt"
+	"strings"
+
+	hdwallet "github.com/divergencetech/go-ethereum-hdwallet"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// An HDPathTemplate is a generalisation of an HDPathPrefix, allowing the
+// account number to be placed anywhere in the derivation path, as denoted by
+// the one and only %d verb. This is necessary for layouts that don't vary the
+// final path component, like that used by Ledger Live.
+type HDPathTemplate string
+
+// LedgerLiveHDPathTemplate is the account layout used by Ledger Live, which
+// varies the BIP44 account, as opposed to the address index.
+const LedgerLiveHDPathTemplate = HDPathTemplate("m/44'/60'/%d'/0/0")
+
+// Path returns the derivation path of the specific account.
+func (t HDPathTemplate) Path(account uint) (accounts.DerivationPath, error) {
+	if n := strings.Count(string(t), "%"); n != 1 || !strings.Contains(string(t), "%d") {
+		return nil, fmt.Errorf("HD path template %q must contain exactly one %%d verb and no other verbs", t)
+	}
+	path, err := hdwallet.ParseDerivationPath(fmt.Sprintf(string(t), account))
+	if err != nil {
+		return nil, fmt.Errorf("parse derivation path: %v", err)
+	}
+	return path, nil
+}
+
+// SignerFromSeedPhrase confirms that the mnemonic is valid under BIP39 and then
+// uses it to derive the private key at t.Path(account).
+func (t HDPathTemplate) SignerFromSeedPhrase(mnemonic, password string, account uint) (*Signer, error) {
+	signers, err := t.signersFromSeedPhrase(mnemonic, password, account, 1)
+	if err != nil {
+		return nil, err
+	}
+	return signers[0], nil
+}
+
+// SignersFromSeedPhrase derives n consecutive accounts, starting at first, from
+// the mnemonic. The BIP39 seed, which is deliberately expensive to compute, is
+// only computed once regardless of n. The returned map is keyed by each
+// Signer's address.
+func (t HDPathTemplate) SignersFromSeedPhrase(mnemonic, password string, first, n uint) (map[common.Address]*Signer, error) {
+	signers, err := t.signersFromSeedPhrase(mnemonic, password, first, n)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[common.Address]*Signer, len(signers))
+	for _, s := range signers {
+		m[s.Address()] = s
+	}
+	return m, nil
+}
+
+// signersFromSeedPhrase is the common implementation of SignerFromSeedPhrase()
+// and SignersFromSeedPhrase(), returning Signers in account order.
+func (t HDPathTemplate) signersFromSeedPhrase(mnemonic, password string, first, n uint) ([]*Signer, error) {
+	if n == 0 {
+		return nil, fmt.Errorf("deriving zero accounts")
+	}
+	if first+n < first {
+		return nil, fmt.Errorf("account range [%d, %d+%d) overflows", first, first, n)
+	}
+
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, password)
+	if err != nil {
+		return nil, fmt.Errorf("create seed from mnemoic: %v", err)
+	}
+	wallet, err := hdwallet.NewFromSeed(seed)
+	if err != nil {
+		return nil, fmt.Errorf("create wallet from seed: %v", err)
+	}
+
+	signers := make([]*Signer, 0, n)
+	for account := first; account < first+n; account++ {
+		path, err := t.Path(account)
+		if err != nil {
+			return nil, err
+		}
+		acc, err := wallet.Derive(path, false)
+		if err != nil {
+			return nil, fmt.Errorf("derive account %d: %v", account, err)
+		}
+		key, err := wallet.PrivateKey(acc)
+		if err != nil {
+			return nil, fmt.Errorf("obtain private key of account %d: %v", account, err)
+		}
+		signers = append(signers, &Signer{
+			backend:  privateKeyBackend{key},
+			mnemonic: mnemonic,
+		})
+	}
+	return signers, nil
+}
+
+// SignersFromSeedPhrase is equivalent to
+// hdp.Template().SignersFromSeedPhrase().
+func (hdp HDPathPrefix) SignersFromSeedPhrase(mnemonic, password string, first, n uint) (map[common.Address]*Signer, error) {
+	return hdp.Template().SignersFromSeedPhrase(mnemonic, password, first, n)
+}
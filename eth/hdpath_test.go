@@ -0,0 +1,165 @@
+package eth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+)
+
+// testMnemonic is the standard BIP39 test vector, for which addresses are
+// widely published.
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestSignersFromSeedPhrase(t *testing.T) {
+	tests := []struct {
+		name     string
+		template HDPathTemplate
+		first, n uint
+	}{
+		{
+			name:     "default prefix",
+			template: DefaultHDPathPrefix.Template(),
+			first:    0,
+			n:        5,
+		},
+		{
+			name:     "default prefix offset",
+			template: DefaultHDPathPrefix.Template(),
+			first:    3,
+			n:        4,
+		},
+		{
+			name:     "Ledger Live",
+			template: LedgerLiveHDPathTemplate,
+			first:    1,
+			n:        3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.template.SignersFromSeedPhrase(testMnemonic, "", tt.first, tt.n)
+			if err != nil {
+				t.Fatalf("%T(%q).SignersFromSeedPhrase(…, %d, %d) error %v", tt.template, tt.template, tt.first, tt.n, err)
+			}
+
+			want := make(map[common.Address]uint)
+			for acc := tt.first; acc < tt.first+tt.n; acc++ {
+				s, err := tt.template.SignerFromSeedPhrase(testMnemonic, "", acc)
+				if err != nil {
+					t.Fatalf("%T(%q).SignerFromSeedPhrase(…, %d) error %v", tt.template, tt.template, acc, err)
+				}
+				want[s.Address()] = acc
+			}
+
+			gotAddrs := make(map[common.Address]uint)
+			for addr, s := range got {
+				if addr != s.Address() {
+					t.Errorf("%T.SignersFromSeedPhrase() keyed Signer with address %v by %v", tt.template, s.Address(), addr)
+				}
+				gotAddrs[addr] = want[addr]
+			}
+			if diff := cmp.Diff(want, gotAddrs); diff != "" {
+				t.Errorf("%T(%q).SignersFromSeedPhrase(…, %d, %d) addresses diff (-want +got):\n%s", tt.template, tt.template, tt.first, tt.n, diff)
+			}
+		})
+	}
+}
+
+func TestHDPathLayouts(t *testing.T) {
+	tests := []struct {
+		name     string
+		template HDPathTemplate
+		account  uint
+		want     common.Address
+	}{
+		{
+			name:     "default account 0",
+			template: DefaultHDPathPrefix.Template(),
+			account:  0,
+			want:     common.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94"),
+		},
+		{
+			name:     "default account 1",
+			template: DefaultHDPathPrefix.Template(),
+			account:  1,
+			want:     common.HexToAddress("0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0"),
+		},
+		{
+			// Ledger Live and the default layout coincide for account 0.
+			name:     "Ledger Live account 0",
+			template: LedgerLiveHDPathTemplate,
+			account:  0,
+			want:     common.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.template.SignerFromSeedPhrase(testMnemonic, "", tt.account)
+			if err != nil {
+				t.Fatalf("%T(%q).SignerFromSeedPhrase(…, %d) error %v", tt.template, tt.template, tt.account, err)
+			}
+			if got := s.Address(); got != tt.want {
+				t.Errorf("%T(%q).SignerFromSeedPhrase(…, %d).Address() got %v; want %v", tt.template, tt.template, tt.account, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Ledger Live differs from default", func(t *testing.T) {
+		ll, err := LedgerLiveHDPathTemplate.SignerFromSeedPhrase(testMnemonic, "", 1)
+		if err != nil {
+			t.Fatalf("LedgerLiveHDPathTemplate.SignerFromSeedPhrase(…, 1) error %v", err)
+		}
+		def, err := DefaultHDPathPrefix.SignerFromSeedPhrase(testMnemonic, "", 1)
+		if err != nil {
+			t.Fatalf("DefaultHDPathPrefix.SignerFromSeedPhrase(…, 1) error %v", err)
+		}
+		if ll.Address() == def.Address() {
+			t.Errorf("Ledger Live and default layouts derived same address %v for account 1", ll.Address())
+		}
+	})
+}
+
+func TestHDPathTemplateErrors(t *testing.T) {
+	tests := []struct {
+		template       HDPathTemplate
+		n              uint
+		errDiffAgainst interface{}
+	}{
+		{
+			template:       "m/44'/60'/0'/0/0",
+			n:              1,
+			errDiffAgainst: "exactly one %d verb",
+		},
+		{
+			template:       "m/44'/60'/%d'/0/%d",
+			n:              1,
+			errDiffAgainst: "exactly one %d verb",
+		},
+		{
+			template:       "m/44'/60'/%s'/0/0",
+			n:              1,
+			errDiffAgainst: "exactly one %d verb",
+		},
+		{
+			template:       "m/44'/60'/%d'/x/0",
+			n:              1,
+			errDiffAgainst: "parse derivation path",
+		},
+		{
+			template:       LedgerLiveHDPathTemplate,
+			n:              0,
+			errDiffAgainst: "zero accounts",
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := tt.template.SignersFromSeedPhrase(testMnemonic, "", 0, tt.n)
+		if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+			t.Errorf("%T(%q).SignersFromSeedPhrase(…, 0, %d) %s", tt.template, tt.template, tt.n, diff)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package eth
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/prf"
+	"github.com/google/tink/go/tink"
+)
+
+// SignerFromKeystore decrypts a go-ethereum encrypted JSON key (as found in a
+// geth keystore directory) with the passphrase, and returns a Signer backed by
+// the decrypted key.
+func SignerFromKeystore(keyJSON []byte, passphrase string) (*Signer, error) {
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt keystore key: %v", err)
+	}
+	return NewSignerFromPrivateKey(key.PrivateKey), nil
+}
+
+// SignerFromKeystoreFile is a convenience wrapper around SignerFromKeystore(),
+// reading the encrypted key from the file.
+func SignerFromKeystoreFile(path, passphrase string) (*Signer, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read keystore file: %v", err)
+	}
+	return SignerFromKeystore(buf, passphrase)
+}
+
+// SignerFromEncryptedPRFKeyset reads a Tink PRF keyset, encrypted with the
+// master AEAD (typically backed by a KMS), and returns
+// hdp.SignerFromPRFSet(<keyset>, input, account).
+func (hdp HDPathPrefix) SignerFromEncryptedPRFKeyset(r keyset.Reader, master tink.AEAD, input []byte, account uint) (*Signer, error) {
+	kh, err := keyset.Read(r, master)
+	if err != nil {
+		return nil, fmt.Errorf("read encrypted keyset: %v", err)
+	}
+	set, err := prf.NewPRFSet(kh)
+	if err != nil {
+		return nil, fmt.Errorf("prf.NewPRFSet(): %v", err)
+	}
+	return hdp.SignerFromPRFSet(set, input, account)
+}
+
+// SignerFromEncryptedPRFKeysetFile is a convenience wrapper around
+// hdp.SignerFromEncryptedPRFKeyset(), reading a JSON-encoded keyset from the
+// file.
+func (hdp HDPathPrefix) SignerFromEncryptedPRFKeysetFile(path string, master tink.AEAD, input []byte, account uint) (*Signer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open keyset file: %v", err)
+	}
+	defer f.Close()
+	return hdp.SignerFromEncryptedPRFKeyset(keyset.NewJSONReader(f), master, input, account)
+}
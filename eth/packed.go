@@ -0,0 +1,96 @@
+package eth
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EncodePacked returns the equivalent of Solidity's abi.encodePacked(args...),
+// for use in building messages that are signed off-chain and checked on-chain.
+//
+// Supported types, and their Solidity equivalents, are:
+//
+//	common.Address, *common.Address    address
+//	common.Hash, [N]byte               bytesN
+//	[]byte                             bytes
+//	string                             string
+//	bool                               bool
+//	uint8 ... uint64, int8 ... int64   uintN / intN of the same width
+//	*big.Int                           uint256 (negative values are rejected)
+//
+// Arrays of dynamic length (other than bytes) are not supported as their
+// packed encoding pads each element, which is a common source of mismatches.
+func EncodePacked(args ...interface{}) ([]byte, error) {
+	var buf []byte
+	for i, a := range args {
+		enc, err := encodePacked(a)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %v", i, err)
+		}
+		buf = append(buf, enc...)
+	}
+	return buf, nil
+}
+
+// bigEndian returns the n least-significant bytes of x, in big-endian order.
+func bigEndian(x uint64, n int) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], x)
+	return buf[8-n:]
+}
+
+// encodePacked returns the packed encoding of a single value.
+func encodePacked(a interface{}) ([]byte, error) {
+	switch a := a.(type) {
+	case common.Address:
+		return a.Bytes(), nil
+	case *common.Address:
+		return a.Bytes(), nil
+	case common.Hash:
+		return a.Bytes(), nil
+	case []byte:
+		return a, nil
+	case string:
+		return []byte(a), nil
+	case bool:
+		if a {
+			return []byte{1}, nil
+		}
+		return []byte{0}, nil
+	case uint8:
+		return []byte{a}, nil
+	case uint16:
+		return bigEndian(uint64(a), 2), nil
+	case uint32:
+		return bigEndian(uint64(a), 4), nil
+	case uint64:
+		return bigEndian(a, 8), nil
+	case int8:
+		return []byte{uint8(a)}, nil
+	case int16:
+		return bigEndian(uint64(uint16(a)), 2), nil
+	case int32:
+		return bigEndian(uint64(uint32(a)), 4), nil
+	case int64:
+		return bigEndian(uint64(a), 8), nil
+	case *big.Int:
+		if a.Sign() == -1 || a.BitLen() > 256 {
+			return nil, fmt.Errorf("%T %v out of uint256 range", a, a)
+		}
+		return a.FillBytes(make([]byte, 32)), nil
+	}
+
+	if v := reflect.ValueOf(a); v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8 {
+		if n := v.Len(); n == 0 || n > 32 {
+			return nil, fmt.Errorf("unsupported fixed-size byte array of length %d", n)
+		}
+		buf := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(buf), v)
+		return buf, nil
+	}
+	return nil, fmt.Errorf("unsupported type %T", a)
+}
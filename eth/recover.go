@@ -0,0 +1,121 @@
+package eth
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// normaliseSignature returns a copy of sig with its yParity (v) shifted to
+// {0,1} as expected by go-ethereum's crypto package. Both the Ethereum {27,28}
+// and the raw {0,1} conventions are accepted. As with OpenZeppelin's ECDSA
+// library, upon which SignatureChecker.sol relies, signatures with s in the
+// upper half of the curve order are rejected as malleable.
+func normaliseSignature(sig []byte) ([]byte, error) {
+	if n := len(sig); n != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid signature length %d; must be %d", n, crypto.SignatureLength)
+	}
+
+	norm := make([]byte, len(sig))
+	copy(norm, sig)
+
+	switch v := norm[64]; v {
+	case 0, 1:
+	case 27, 28:
+		norm[64] -= 27
+	default:
+		return nil, fmt.Errorf("invalid signature yParity (v) %d", v)
+	}
+
+	r := new(big.Int).SetBytes(norm[:32])
+	s := new(big.Int).SetBytes(norm[32:64])
+	if !crypto.ValidateSignatureValues(norm[64], r, s, true) {
+		return nil, fmt.Errorf("invalid signature values r = %#x, s = %#x", r, s)
+	}
+	return norm, nil
+}
+
+// recoverAddress returns the address of the account that signed buf, under the
+// same options as used by Signer.sign(). Nonces, if any, MUST already be
+// appended to buf.
+func recoverAddress(buf, sig []byte, opts signOpts) (common.Address, error) {
+	norm, err := normaliseSignature(sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	msg := opts.message(buf)
+	if n := len(msg); n != 32 {
+		return common.Address{}, fmt.Errorf("signed message must be 32 bytes; got %d", n)
+	}
+
+	pub, err := crypto.SigToPub(msg, norm)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("recover public key: %v", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// RecoverRaw returns the address of the account that produced sig with
+// Signer.RawSign(buf). As ECDSA operates on 32-byte values, buf MUST be 32
+// bytes long.
+func RecoverRaw(buf, sig []byte) (common.Address, error) {
+	return recoverAddress(buf, sig, signOpts{
+		raw:       true,
+		personal:  false,
+		withNonce: false,
+	})
+}
+
+// Recover returns the address of the account that produced sig with
+// Signer.Sign(buf).
+func Recover(buf, sig []byte) (common.Address, error) {
+	return recoverAddress(buf, sig, signOpts{
+		raw:       false,
+		personal:  false,
+		withNonce: false,
+	})
+}
+
+// RecoverPersonal returns the address of the account that produced sig with
+// Signer.PersonalSign(buf). This is equivalent to the address recovered by
+// SignatureChecker.sol for a message generated from buf.
+func RecoverPersonal(buf, sig []byte) (common.Address, error) {
+	return recoverAddress(buf, sig, signOpts{
+		raw:       false,
+		personal:  true,
+		withNonce: false,
+	})
+}
+
+// RecoverPersonalWithNonce returns the address of the account that produced
+// sig and nonce with Signer.PersonalSignWithNonce(buf).
+func RecoverPersonalWithNonce(buf []byte, nonce [32]byte, sig []byte) (common.Address, error) {
+	msg := make([]byte, 0, len(buf)+len(nonce))
+	msg = append(msg, buf...)
+	msg = append(msg, nonce[:]...)
+
+	return recoverAddress(msg, sig, signOpts{
+		raw:       false,
+		personal:  true,
+		withNonce: true,
+	})
+}
+
+// RecoverPersonalAddress returns the address of the account that produced sig
+// with Signer.PersonalSignAddress(addr).
+func RecoverPersonalAddress(addr common.Address, sig []byte) (common.Address, error) {
+	return RecoverPersonal(addr.Bytes(), sig)
+}
+
+// RecoverTypedData returns the address of the account that produced sig with
+// Signer.SignTypedData(td).
+func RecoverTypedData(td *TypedData, sig []byte) (common.Address, error) {
+	h, err := td.Hash()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return RecoverRaw(h.Digest.Bytes(), sig)
+}
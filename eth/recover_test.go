@@ -0,0 +1,194 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/h-fam/errdiff"
+)
+
+func TestRecover(t *testing.T) {
+	signer, err := NewSigner(256)
+	if err != nil {
+		t.Fatalf("NewSigner(256) error %v", err)
+	}
+	want := signer.Address()
+
+	msg := []byte("hello")
+	hash := crypto.Keccak256(msg)
+	addr := common.HexToAddress("0x71e059FA4594b69200541A189010188eDFFbC34D")
+
+	// The nonce is generated at signing time so has to be plumbed through.
+	var nonce [32]byte
+
+	tests := []struct {
+		name    string
+		sign    func() ([]byte, error)
+		recover func(sig []byte) (common.Address, error)
+	}{
+		{
+			name: "raw",
+			sign: func() ([]byte, error) {
+				return signer.RawSign(hash)
+			},
+			recover: func(sig []byte) (common.Address, error) {
+				return RecoverRaw(hash, sig)
+			},
+		},
+		{
+			name: "keccak",
+			sign: func() ([]byte, error) {
+				return signer.Sign(msg)
+			},
+			recover: func(sig []byte) (common.Address, error) {
+				return Recover(msg, sig)
+			},
+		},
+		{
+			name: "personal",
+			sign: func() ([]byte, error) {
+				return signer.PersonalSign(msg)
+			},
+			recover: func(sig []byte) (common.Address, error) {
+				return RecoverPersonal(msg, sig)
+			},
+		},
+		{
+			name: "personal address",
+			sign: func() ([]byte, error) {
+				return signer.PersonalSignAddress(addr)
+			},
+			recover: func(sig []byte) (common.Address, error) {
+				return RecoverPersonalAddress(addr, sig)
+			},
+		},
+		{
+			name: "typed data",
+			sign: func() ([]byte, error) {
+				sig, err := signer.SignTypedData(mailTypedData())
+				if err != nil {
+					return nil, err
+				}
+				return sig.Signature, nil
+			},
+			recover: func(sig []byte) (common.Address, error) {
+				return RecoverTypedData(mailTypedData(), sig)
+			},
+		},
+		{
+			name: "personal with nonce",
+			sign: func() ([]byte, error) {
+				sig, n, err := signer.PersonalSignWithNonce(msg)
+				nonce = n
+				return sig, err
+			},
+			recover: func(sig []byte) (common.Address, error) {
+				return RecoverPersonalWithNonce(msg, nonce, sig)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := tt.sign()
+			if err != nil {
+				t.Fatalf("sign() error %v", err)
+			}
+
+			zeroOne := make([]byte, len(sig))
+			copy(zeroOne, sig)
+			zeroOne[64] -= 27
+
+			for _, s := range [][]byte{sig, zeroOne} {
+				got, err := tt.recover(s)
+				if err != nil {
+					t.Fatalf("recover([v = %d]) error %v", s[64], err)
+				}
+				if got != want {
+					t.Errorf("recover([v = %d]) got %v; want %v", s[64], got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRecoverWrongMessage(t *testing.T) {
+	signer, err := NewSigner(256)
+	if err != nil {
+		t.Fatalf("NewSigner(256) error %v", err)
+	}
+	sig, err := signer.PersonalSign([]byte("hello"))
+	if err != nil {
+		t.Fatalf("%T.PersonalSign() error %v", signer, err)
+	}
+
+	got, err := RecoverPersonal([]byte("Hello"), sig)
+	if err != nil {
+		t.Fatalf("RecoverPersonal() error %v", err)
+	}
+	if got == signer.Address() {
+		t.Errorf("RecoverPersonal() with different message got signer's address %v", got)
+	}
+}
+
+func TestRecoverInvalidSignature(t *testing.T) {
+	signer, err := NewSigner(256)
+	if err != nil {
+		t.Fatalf("NewSigner(256) error %v", err)
+	}
+	msg := []byte("hello")
+	sig, err := signer.PersonalSign(msg)
+	if err != nil {
+		t.Fatalf("%T.PersonalSign() error %v", signer, err)
+	}
+
+	// Flipping s to n-s and the parity of v results in an equally valid
+	// signature that OpenZeppelin's ECDSA library rejects.
+	malleable := make([]byte, len(sig))
+	copy(malleable, sig)
+	s := new(big.Int).Sub(crypto.S256().Params().N, new(big.Int).SetBytes(sig[32:64]))
+	s.FillBytes(malleable[32:64])
+	malleable[64] = 27 + (28 - sig[64])
+
+	badV := make([]byte, len(sig))
+	copy(badV, sig)
+	badV[64] = 29
+
+	tests := []struct {
+		name           string
+		sig            []byte
+		errDiffAgainst interface{}
+	}{
+		{
+			name:           "valid",
+			sig:            sig,
+			errDiffAgainst: nil,
+		},
+		{
+			name:           "short",
+			sig:            sig[:64],
+			errDiffAgainst: "invalid signature length",
+		},
+		{
+			name:           "invalid v",
+			sig:            badV,
+			errDiffAgainst: "invalid signature yParity",
+		},
+		{
+			name:           "high s",
+			sig:            malleable,
+			errDiffAgainst: "invalid signature values",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RecoverPersonal(msg, tt.sig)
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Errorf("RecoverPersonal() %s", diff)
+			}
+		})
+	}
+}
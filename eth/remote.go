@@ -0,0 +1,76 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// A RemoteBackend is a KeyBackend that delegates signing to an external
+// process over JSON-RPC, using the account_signData method of Clef's external
+// API. The private key is therefore never held by the current process.
+//
+// Clef deliberately doesn't support signing of arbitrary hashes, so a Signer
+// backed by a RemoteBackend can only produce EIP-191 personal signatures (e.g.
+// PersonalSign(), PersonalSignWithNonce(), and vouchers); all other signing
+// methods will return an error.
+type RemoteBackend struct {
+	client  *rpc.Client
+	account common.Address
+	// Timeout, if non-zero, limits the duration of each signing request.
+	Timeout time.Duration
+}
+
+var _ TextSigner = (*RemoteBackend)(nil)
+
+// NewRemoteBackend returns a RemoteBackend that requests signatures from the
+// account via the client.
+func NewRemoteBackend(client *rpc.Client, account common.Address) *RemoteBackend {
+	return &RemoteBackend{
+		client:  client,
+		account: account,
+	}
+}
+
+// DialRemoteSigner is a convenience function that dials the JSON-RPC endpoint
+// (e.g. http://localhost:8550 or a Clef IPC path) and returns a Signer backed
+// by a RemoteBackend for the account.
+func DialRemoteSigner(ctx context.Context, endpoint string, account common.Address) (*Signer, error) {
+	client, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("rpc.DialContext(%q): %v", endpoint, err)
+	}
+	return NewSignerFromBackend(NewRemoteBackend(client, account)), nil
+}
+
+// Address returns the account for which signatures are requested.
+func (b *RemoteBackend) Address() common.Address {
+	return b.account
+}
+
+// SignHash always returns an error as Clef doesn't support signing of
+// arbitrary hashes.
+func (b *RemoteBackend) SignHash([]byte) ([]byte, error) {
+	return nil, fmt.Errorf("%T only supports EIP-191 personal signatures", b)
+}
+
+// SignText requests a signature of the personal message from the remote
+// signer, using the text/plain content type.
+func (b *RemoteBackend) SignText(msg []byte) ([]byte, error) {
+	ctx := context.Background()
+	if b.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.Timeout)
+		defer cancel()
+	}
+
+	var sig hexutil.Bytes
+	if err := b.client.CallContext(ctx, &sig, "account_signData", "text/plain", b.account, hexutil.Bytes(msg)); err != nil {
+		return nil, fmt.Errorf("account_signData: %v", err)
+	}
+	return sig, nil
+}
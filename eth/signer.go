@@ -1,7 +1,7 @@
 package eth
 
 import (
-	"crypto/ecdsa"
+	"context"
 	"crypto/rand"
 	"fmt"
 	"math/big"
@@ -11,14 +11,15 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/google/tink/go/prf"
 )
 
-// A Signer abstracts signing of arbitrary messages by wrapping an ECDSA private
-// key and, optionally, its associated BIP39 mnemonic.
+// A Signer abstracts signing of arbitrary messages by wrapping a KeyBackend
+// and, optionally, the BIP39 mnemonic from which its private key was derived.
 type Signer struct {
-	key      *ecdsa.PrivateKey
+	backend  KeyBackend
 	mnemonic string
 }
 
@@ -58,29 +59,13 @@ const DefaultHDPathPrefix = HDPathPrefix("m/44'/60'/0'/0/")
 // SignerFromSeedPhrase confirms that the mnemonic is valid under BIP39 and then
 // uses it to derive a private key (see HDPathF)
 func (hdp HDPathPrefix) SignerFromSeedPhrase(mnemonic, password string, account uint) (*Signer, error) {
-	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, password)
-	if err != nil {
-		return nil, fmt.Errorf("create seed from mnemoic: %v", err)
-	}
-	wallet, err := hdwallet.NewFromSeed(seed)
-	if err != nil {
-		return nil, fmt.Errorf("create wallet from seed: %v", err)
-	}
-
-	path, err := hdwallet.ParseDerivationPath(fmt.Sprintf("%s%d", hdp, account))
-	if err != nil {
-		return nil, fmt.Errorf("parse derivation path: %v", err)
-	}
-	acc, err := wallet.Derive(path, false)
-	if err != nil {
-		return nil, fmt.Errorf("derive account: %v", err)
-	}
+	return hdp.Template().SignerFromSeedPhrase(mnemonic, password, account)
+}
 
-	key, err := wallet.PrivateKey(acc)
-	if err != nil {
-		return nil, fmt.Errorf("obtain private key: %v", err)
-	}
-	return &Signer{key, mnemonic}, nil
+// Template returns the HDPathTemplate equivalent of the prefix, with the
+// account number as the final path component.
+func (hdp HDPathPrefix) Template() HDPathTemplate {
+	return HDPathTemplate(string(hdp) + "%d")
 }
 
 // SignerFromPRF deterministically derives a private key from the pseudo-random
@@ -119,15 +104,15 @@ func (s *Signer) String() string {
 	return s.Address().String()
 }
 
-// Mnemonic returns the mnemonic used to derive the Signer's private key. USE
-// WITH CAUTION.
+// Mnemonic returns the mnemonic used to derive the Signer's private key, or an
+// empty string if the Signer wasn't derived from a mnemonic. USE WITH CAUTION.
 func (s *Signer) Mnemonic() string {
 	return s.mnemonic
 }
 
 // Address returns the Signer's public key converted to an Ethereum address.
 func (s *Signer) Address() common.Address {
-	return crypto.PubkeyToAddress(s.key.PublicKey)
+	return s.backend.Address()
 }
 
 // AppendRandomNonce appends random 32 bytes to the buffer, commonly used in
@@ -154,6 +139,19 @@ type signOpts struct {
 	withNonce bool
 }
 
+// message returns the buffer that is passed to ECDSA when signing or
+// recovering buf under the options. Nonces are not appended by message() as
+// they are the responsibility of the caller.
+func (opts signOpts) message(buf []byte) []byte {
+	if opts.personal {
+		buf = WithPersonalMessagePrefix(buf)
+	}
+	if !opts.raw {
+		buf = crypto.Keccak256(buf)
+	}
+	return buf
+}
+
 // sign signs a given buffer depending on the chosen options:
 // withNonce = true, appends a nonce to the message
 // personal = true, adds a prefix to the message to conform to the EIP-191
@@ -172,21 +170,24 @@ func (s *Signer) sign(buf []byte, opts signOpts) ([]byte, *[32]byte, error) {
 		}
 	}
 
-	if opts.personal {
-		buf = WithPersonalMessagePrefix(buf)
+	var sig []byte
+	if ts, ok := s.backend.(TextSigner); ok && opts.personal && !opts.raw {
+		sig, err = ts.SignText(buf)
+	} else {
+		sig, err = s.backend.SignHash(opts.message(buf))
 	}
-
-	if !opts.raw {
-		buf = crypto.Keccak256(buf)
-	}
-
-	sig, err := crypto.Sign(buf, s.key)
 	if err != nil {
 		return nil, nil, err
 	}
+	if n := len(sig); n != crypto.SignatureLength {
+		return nil, nil, fmt.Errorf("%T returned signature of length %d; want %d", s.backend, n, crypto.SignatureLength)
+	}
 
-	// yParities are shifted by 27 for Ethereum signatures by convention.
-	sig[64] += 27
+	// yParities are shifted by 27 for Ethereum signatures by convention, but
+	// backends may have already done so.
+	if sig[64] < 27 {
+		sig[64] += 27
+	}
 	return sig, nonce, nil
 }
 
@@ -243,8 +244,26 @@ func (s *Signer) PersonalSignAddress(addr common.Address) ([]byte, error) {
 	return s.PersonalSign(addr.Bytes())
 }
 
-// TransactorWithChainID returns bind.NewKeyedTransactorWithChainID(<key>,
-// chainID) where <key> is the Signer's private key.
+// TransactorWithChainID returns the equivalent of
+// bind.NewKeyedTransactorWithChainID(<key>, chainID) where <key> is the
+// Signer's private key. Transactions are signed by the Signer's KeyBackend, so
+// the private key need not be accessible.
 func (s *Signer) TransactorWithChainID(chainID *big.Int) (*bind.TransactOpts, error) {
-	return bind.NewKeyedTransactorWithChainID(s.key, chainID)
+	if chainID == nil {
+		return nil, bind.ErrNoChainID
+	}
+
+	signer := types.LatestSignerForChainID(chainID)
+	from := s.Address()
+
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if addr != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			return s.signTx(signer, tx)
+		},
+		Context: context.Background(),
+	}, nil
 }
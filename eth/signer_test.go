@@ -120,6 +120,19 @@ func TestDeterministicSigner(t *testing.T) {
 	}
 }
 
+func TestSignerFromEncryptedPRFKeyset(t *testing.T) {
+	r := keyset.NewJSONReader(strings.NewReader(testOnlyPRFKey0))
+	s, err := DefaultHDPathPrefix.SignerFromEncryptedPRFKeyset(r, nonSecureAEADOnlyForTesting{}, nil, 1)
+	if err != nil {
+		t.Fatalf("SignerFromEncryptedPRFKeyset() error %v", err)
+	}
+
+	// See TestDeterministicSigner.
+	if got, want := s.Address(), common.HexToAddress("0x8000B0045d0Ce1265d74FFCF60d4311c565C140B"); got != want {
+		t.Errorf("SignerFromEncryptedPRFKeyset([testOnlyPRFKey0], nil, 1) got address %v; want %v", got, want)
+	}
+}
+
 type nonSecureAEADOnlyForTesting struct{}
 
 var _ tink.AEAD = nonSecureAEADOnlyForTesting{}
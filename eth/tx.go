@@ -0,0 +1,162 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TxParams are the explicit fields of a transaction to be signed offline, i.e.
+// without access to a node for nonce, gas or fee estimation.
+//
+// The transaction type is determined by the fee fields that are set:
+//   - GasPrice only: legacy (EIP-155) transaction, or EIP-2930 if AccessList
+//     is non-nil;
+//   - GasTipCap and GasFeeCap: EIP-1559 transaction.
+//
+// Setting both GasPrice and either of the EIP-1559 fee fields is an error, as
+// is setting neither.
+type TxParams struct {
+	Nonce uint64
+	// To is the recipient of the transaction; nil for contract deployments.
+	To    *common.Address
+	Value *big.Int
+	Gas   uint64
+	Data  []byte
+
+	GasPrice   *big.Int
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	AccessList types.AccessList
+}
+
+// Errors returned by TxParams.TxData() when fee fields are incorrectly set.
+var (
+	ErrNoFees    = errors.New("neither GasPrice nor GasTipCap+GasFeeCap set")
+	ErrMixedFees = errors.New("both GasPrice and GasTipCap/GasFeeCap set")
+)
+
+// TxData returns the go-ethereum transaction data corresponding to p, with its
+// type determined as described in the TxParams documentation. The chain ID is
+// only included in EIP-2930 and EIP-1559 transactions; legacy transactions
+// instead include it in the signature, as per EIP-155.
+func (p *TxParams) TxData(chainID *big.Int) (types.TxData, error) {
+	if chainID == nil {
+		return nil, bind.ErrNoChainID
+	}
+
+	dynamic := p.GasTipCap != nil || p.GasFeeCap != nil
+	switch {
+	case p.GasPrice == nil && !dynamic:
+		return nil, ErrNoFees
+	case p.GasPrice != nil && dynamic:
+		return nil, ErrMixedFees
+	case dynamic && (p.GasTipCap == nil || p.GasFeeCap == nil):
+		return nil, fmt.Errorf("both GasTipCap and GasFeeCap must be set for EIP-1559 transactions")
+	case dynamic && p.GasTipCap.Cmp(p.GasFeeCap) == 1:
+		return nil, fmt.Errorf("GasTipCap %d exceeds GasFeeCap %d", p.GasTipCap, p.GasFeeCap)
+	}
+
+	value := new(big.Int)
+	if p.Value != nil {
+		value.Set(p.Value)
+	}
+	data := append([]byte{}, p.Data...)
+
+	switch {
+	case dynamic:
+		return &types.DynamicFeeTx{
+			ChainID:    new(big.Int).Set(chainID),
+			Nonce:      p.Nonce,
+			GasTipCap:  new(big.Int).Set(p.GasTipCap),
+			GasFeeCap:  new(big.Int).Set(p.GasFeeCap),
+			Gas:        p.Gas,
+			To:         copyAddress(p.To),
+			Value:      value,
+			Data:       data,
+			AccessList: p.AccessList,
+		}, nil
+	case p.AccessList != nil:
+		return &types.AccessListTx{
+			ChainID:    new(big.Int).Set(chainID),
+			Nonce:      p.Nonce,
+			GasPrice:   new(big.Int).Set(p.GasPrice),
+			Gas:        p.Gas,
+			To:         copyAddress(p.To),
+			Value:      value,
+			Data:       data,
+			AccessList: p.AccessList,
+		}, nil
+	default:
+		return &types.LegacyTx{
+			Nonce:    p.Nonce,
+			GasPrice: new(big.Int).Set(p.GasPrice),
+			Gas:      p.Gas,
+			To:       copyAddress(p.To),
+			Value:    value,
+			Data:     data,
+		}, nil
+	}
+}
+
+// copyAddress returns a pointer to a copy of *a, or nil if a is nil.
+func copyAddress(a *common.Address) *common.Address {
+	if a == nil {
+		return nil
+	}
+	cp := *a
+	return &cp
+}
+
+// SignTx builds a transaction from p and signs it for the specific chain.
+func (s *Signer) SignTx(chainID *big.Int, p *TxParams) (*types.Transaction, error) {
+	data, err := p.TxData(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return s.signTx(types.LatestSignerForChainID(chainID), types.NewTx(data))
+}
+
+// SignTxBinary returns s.SignTx(), encoded in its canonical binary form, ready
+// for broadcast with eth_sendRawTransaction. Legacy transactions are plain RLP,
+// while typed transactions are the EIP-2718 envelope, i.e. the type byte
+// followed by the RLP payload.
+func (s *Signer) SignTxBinary(chainID *big.Int, p *TxParams) ([]byte, error) {
+	tx, err := s.SignTx(chainID, p)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("%T.MarshalBinary(): %v", tx, err)
+	}
+	return buf, nil
+}
+
+// signTx signs the transaction with the Signer's KeyBackend.
+func (s *Signer) signTx(signer types.Signer, tx *types.Transaction) (*types.Transaction, error) {
+	sig, err := s.backend.SignHash(signer.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, sig)
+}
+
+// DecodeSignedTx decodes a transaction as returned by Signer.SignTxBinary(),
+// returning it along with its sender as recovered from the signature. This
+// allows a signed transaction to be inspected before broadcast.
+func DecodeSignedTx(buf []byte) (*types.Transaction, common.Address, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(buf); err != nil {
+		return nil, common.Address{}, fmt.Errorf("%T.UnmarshalBinary(): %v", tx, err)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, common.Address{}, fmt.Errorf("recover sender: %v", err)
+	}
+	return tx, from, nil
+}
@@ -0,0 +1,159 @@
+package eth_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/h-fam/errdiff"
+)
+
+func TestSignTxBinary(t *testing.T) {
+	ctx := context.Background()
+	sim := ethtest.NewSimulatedBackendTB(t, 1)
+	chainID := sim.Blockchain().Config().ChainID
+
+	// The Signer is only ever used offline, with the simulated backend acting
+	// as the broadcasting node.
+	signer := eth.NewSignerFromPrivateKey(sim.PrivateKey(0))
+	recipient := common.HexToAddress("0x71e059FA4594b69200541A189010188eDFFbC34D")
+
+	const gas = 30000 // sufficient for a plain transfer with an access list
+	gasPrice := eth.EtherFraction(10, 1e9)
+
+	tests := []struct {
+		name     string
+		params   eth.TxParams
+		wantType uint8
+	}{
+		{
+			name: "legacy",
+			params: eth.TxParams{
+				Nonce:    0,
+				To:       &recipient,
+				Value:    eth.Ether(1),
+				Gas:      gas,
+				GasPrice: gasPrice,
+			},
+			wantType: types.LegacyTxType,
+		},
+		{
+			name: "EIP-2930",
+			params: eth.TxParams{
+				Nonce:    1,
+				To:       &recipient,
+				Value:    eth.Ether(1),
+				Gas:      gas,
+				GasPrice: gasPrice,
+				AccessList: types.AccessList{
+					{Address: recipient, StorageKeys: []common.Hash{{}}},
+				},
+			},
+			wantType: types.AccessListTxType,
+		},
+		{
+			name: "EIP-1559",
+			params: eth.TxParams{
+				Nonce:     2,
+				To:        &recipient,
+				Value:     eth.Ether(1),
+				Gas:       gas,
+				GasTipCap: eth.EtherFraction(1, 1e9),
+				GasFeeCap: gasPrice,
+			},
+			wantType: types.DynamicFeeTxType,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := signer.SignTxBinary(chainID, &tt.params)
+			if err != nil {
+				t.Fatalf("%T.SignTxBinary(%d, %+v) error %v", signer, chainID, tt.params, err)
+			}
+
+			tx, from, err := eth.DecodeSignedTx(buf)
+			if err != nil {
+				t.Fatalf("DecodeSignedTx(%T.SignTxBinary()) error %v", signer, err)
+			}
+			if got, want := from, signer.Address(); got != want {
+				t.Errorf("DecodeSignedTx(%T.SignTxBinary()) got sender %v; want %v", signer, got, want)
+			}
+			if got := tx.Type(); got != tt.wantType {
+				t.Errorf("DecodeSignedTx(%T.SignTxBinary()) got type %d; want %d", signer, got, tt.wantType)
+			}
+			if got := tx.ChainId(); got.Cmp(chainID) != 0 {
+				t.Errorf("DecodeSignedTx(%T.SignTxBinary()) got chain ID %d; want %d", signer, got, chainID)
+			}
+
+			if err := sim.SendTransaction(ctx, tx); err != nil {
+				t.Fatalf("%T.SendTransaction([decoded tx]) error %v", sim, err)
+			}
+			if got, want := sim.BalanceOf(ctx, t, recipient), eth.Ether(int64(i+1)); got.Cmp(want) != 0 {
+				t.Errorf("%T.BalanceOf(recipient) got %d; want %d", sim, got, want)
+			}
+		})
+	}
+}
+
+func TestSignTxErrors(t *testing.T) {
+	signer, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		chainID        *big.Int
+		params         eth.TxParams
+		errDiffAgainst interface{}
+	}{
+		{
+			name:           "nil chain ID",
+			params:         eth.TxParams{GasPrice: big.NewInt(1)},
+			errDiffAgainst: "chain id",
+		},
+		{
+			name:           "no fees",
+			chainID:        big.NewInt(1),
+			errDiffAgainst: eth.ErrNoFees,
+		},
+		{
+			name:    "mixed fees",
+			chainID: big.NewInt(1),
+			params: eth.TxParams{
+				GasPrice:  big.NewInt(1),
+				GasFeeCap: big.NewInt(1),
+			},
+			errDiffAgainst: eth.ErrMixedFees,
+		},
+		{
+			name:           "missing tip cap",
+			chainID:        big.NewInt(1),
+			params:         eth.TxParams{GasFeeCap: big.NewInt(1)},
+			errDiffAgainst: "both GasTipCap and GasFeeCap",
+		},
+		{
+			name:    "tip exceeds fee cap",
+			chainID: big.NewInt(1),
+			params: eth.TxParams{
+				GasTipCap: big.NewInt(2),
+				GasFeeCap: big.NewInt(1),
+			},
+			errDiffAgainst: "exceeds GasFeeCap",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := signer.SignTxBinary(tt.chainID, &tt.params)
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Errorf("%T.SignTxBinary(%v, %+v) %s", signer, tt.chainID, tt.params, diff)
+			}
+		})
+	}
+}
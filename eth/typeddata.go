@@ -0,0 +1,211 @@
+package eth
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// A TypedDataDomain is an EIP-712 domain. Only non-zero fields are included in
+// the EIP712Domain type, in the order defined by the standard.
+type TypedDataDomain struct {
+	Name              string
+	Version           string
+	ChainID           *big.Int
+	VerifyingContract *common.Address
+	Salt              *[32]byte
+}
+
+// types returns the EIP712Domain type description corresponding to the
+// domain's non-zero fields.
+func (d *TypedDataDomain) types() []apitypes.Type {
+	var t []apitypes.Type
+	if d.Name != "" {
+		t = append(t, apitypes.Type{Name: "name", Type: "string"})
+	}
+	if d.Version != "" {
+		t = append(t, apitypes.Type{Name: "version", Type: "string"})
+	}
+	if d.ChainID != nil {
+		t = append(t, apitypes.Type{Name: "chainId", Type: "uint256"})
+	}
+	if d.VerifyingContract != nil {
+		t = append(t, apitypes.Type{Name: "verifyingContract", Type: "address"})
+	}
+	if d.Salt != nil {
+		t = append(t, apitypes.Type{Name: "salt", Type: "bytes32"})
+	}
+	return t
+}
+
+// apiDomain converts the domain into its go-ethereum equivalent.
+func (d *TypedDataDomain) apiDomain() apitypes.TypedDataDomain {
+	dom := apitypes.TypedDataDomain{
+		Name:    d.Name,
+		Version: d.Version,
+	}
+	if d.ChainID != nil {
+		dom.ChainId = (*math.HexOrDecimal256)(d.ChainID)
+	}
+	if d.VerifyingContract != nil {
+		dom.VerifyingContract = d.VerifyingContract.Hex()
+	}
+	if d.Salt != nil {
+		dom.Salt = hexutil.Encode(d.Salt[:])
+	}
+	return dom
+}
+
+// TypedData describes an EIP-712 structured message to be hashed and signed.
+//
+// Types MUST NOT include the EIP712Domain type as it is derived from the
+// Domain. Message values may be any of the types accepted by go-ethereum's
+// apitypes package as well as common.Address, *big.Int, Go integer types, and
+// fixed-size byte arrays, all of which are converted as necessary.
+type TypedData struct {
+	Domain      TypedDataDomain
+	Types       apitypes.Types
+	PrimaryType string
+	Message     map[string]interface{}
+}
+
+// eip712DomainType is the name of the type describing an EIP-712 domain.
+const eip712DomainType = "EIP712Domain"
+
+// apiTypedData converts td into its go-ethereum equivalent.
+func (td *TypedData) apiTypedData() (*apitypes.TypedData, error) {
+	if _, ok := td.Types[eip712DomainType]; ok {
+		return nil, fmt.Errorf("%T.Types must not include %q; it is derived from the Domain", td, eip712DomainType)
+	}
+	types := make(apitypes.Types, len(td.Types)+1)
+	for k, v := range td.Types {
+		types[k] = v
+	}
+	types[eip712DomainType] = td.Domain.types()
+
+	msg, ok := normaliseTypedValue(td.Message).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%T.Message normalised to unexpected type", td)
+	}
+
+	return &apitypes.TypedData{
+		Types:       types,
+		PrimaryType: td.PrimaryType,
+		Domain:      td.Domain.apiDomain(),
+		Message:     msg,
+	}, nil
+}
+
+// normaliseTypedValue recursively converts v into the types expected by
+// go-ethereum's apitypes encoder.
+func normaliseTypedValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			out[k] = normaliseTypedValue(x)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, x := range v {
+			out[i] = normaliseTypedValue(x)
+		}
+		return out
+	case []map[string]interface{}:
+		out := make([]interface{}, len(v))
+		for i, x := range v {
+			out[i] = normaliseTypedValue(x)
+		}
+		return out
+	case common.Address:
+		return v.Hex()
+	case *common.Address:
+		return v.Hex()
+	case common.Hash:
+		return v.Bytes()
+	case *big.Int:
+		return (*math.HexOrDecimal256)(v)
+	case int, int8, int16, int32, int64:
+		return (*math.HexOrDecimal256)(big.NewInt(reflect.ValueOf(v).Int()))
+	case uint, uint8, uint16, uint32, uint64:
+		return (*math.HexOrDecimal256)(new(big.Int).SetUint64(reflect.ValueOf(v).Uint()))
+	}
+
+	// Fixed-size byte arrays (e.g. [32]byte) are otherwise unsupported.
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+		buf := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(buf), rv)
+		return buf
+	}
+	return v
+}
+
+// TypedDataHashes carries the intermediate and final hashes of an EIP-712
+// message.
+type TypedDataHashes struct {
+	DomainSeparator common.Hash
+	StructHash      common.Hash
+	// Digest is keccak256("\x19\x01" ‖ DomainSeparator ‖ StructHash), which is
+	// the value that is actually signed.
+	Digest common.Hash
+}
+
+// Hash computes the EIP-712 domain separator, the hash of the primary-type
+// struct, and the digest that is signed.
+func (td *TypedData) Hash() (*TypedDataHashes, error) {
+	data, err := td.apiTypedData()
+	if err != nil {
+		return nil, err
+	}
+
+	dom, err := data.HashStruct(eip712DomainType, data.Domain.Map())
+	if err != nil {
+		return nil, fmt.Errorf("hash %s: %v", eip712DomainType, err)
+	}
+	msg, err := data.HashStruct(data.PrimaryType, data.Message)
+	if err != nil {
+		return nil, fmt.Errorf("hash %s: %v", data.PrimaryType, err)
+	}
+
+	h := &TypedDataHashes{
+		DomainSeparator: common.BytesToHash(dom),
+		StructHash:      common.BytesToHash(msg),
+	}
+	h.Digest = crypto.Keccak256Hash([]byte("\x19\x01"), dom, msg)
+	return h, nil
+}
+
+// A TypedDataSignature is an EIP-712 signature along with the hashes from which
+// it was derived.
+type TypedDataSignature struct {
+	TypedDataHashes
+	Signature []byte
+}
+
+// SignTypedData returns an EIP-712 signature of the typed data, as would be
+// produced by a wallet's eth_signTypedData_v4 method.
+func (s *Signer) SignTypedData(td *TypedData) (*TypedDataSignature, error) {
+	h, err := td.Hash()
+	if err != nil {
+		return nil, err
+	}
+	sig, _, err := s.sign(h.Digest.Bytes(), signOpts{
+		raw:       true,
+		personal:  false,
+		withNonce: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &TypedDataSignature{
+		TypedDataHashes: *h,
+		Signature:       sig,
+	}, nil
+}
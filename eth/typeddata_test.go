@@ -0,0 +1,96 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/google/go-cmp/cmp"
+)
+
+// mailTypedData returns the example message from the EIP-712 specification.
+func mailTypedData() *TypedData {
+	contract := common.HexToAddress("0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC")
+
+	return &TypedData{
+		Domain: TypedDataDomain{
+			Name:              "Ether Mail",
+			Version:           "1",
+			ChainID:           big.NewInt(1),
+			VerifyingContract: &contract,
+		},
+		Types: apitypes.Types{
+			"Person": {
+				{Name: "name", Type: "string"},
+				{Name: "wallet", Type: "address"},
+			},
+			"Mail": {
+				{Name: "from", Type: "Person"},
+				{Name: "to", Type: "Person"},
+				{Name: "contents", Type: "string"},
+			},
+		},
+		PrimaryType: "Mail",
+		Message: map[string]interface{}{
+			"from": map[string]interface{}{
+				"name":   "Cow",
+				"wallet": common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"),
+			},
+			"to": map[string]interface{}{
+				"name":   "Bob",
+				"wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB",
+			},
+			"contents": "Hello, Bob!",
+		},
+	}
+}
+
+func TestTypedDataHash(t *testing.T) {
+	// https://eips.ethereum.org/EIPS/eip-712 reference implementation.
+	want := &TypedDataHashes{
+		DomainSeparator: common.HexToHash("0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"),
+		StructHash:      common.HexToHash("0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"),
+		Digest:          common.HexToHash("0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"),
+	}
+
+	td := mailTypedData()
+	got, err := td.Hash()
+	if err != nil {
+		t.Fatalf("%T.Hash() error %v", td, err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("%T.Hash() diff (-want +got):\n%s", td, diff)
+	}
+}
+
+func TestSignTypedData(t *testing.T) {
+	// The EIP-712 reference implementation uses keccak256("cow") as the
+	// private key.
+	key, err := crypto.ToECDSA(crypto.Keccak256([]byte("cow")))
+	if err != nil {
+		t.Fatalf("crypto.ToECDSA() error %v", err)
+	}
+	s := NewSignerFromPrivateKey(key)
+
+	td := mailTypedData()
+	got, err := s.SignTypedData(td)
+	if err != nil {
+		t.Fatalf("%T.SignTypedData() error %v", s, err)
+	}
+
+	want := common.FromHex("0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b915621c")
+	if diff := cmp.Diff(want, got.Signature); diff != "" {
+		t.Errorf("%T.SignTypedData() signature diff (-want +got):\n%s", s, diff)
+	}
+}
+
+func TestTypedDataRejectsExplicitDomainType(t *testing.T) {
+	td := mailTypedData()
+	td.Types[eip712DomainType] = []apitypes.Type{{Name: "name", Type: "string"}}
+
+	if _, err := td.Hash(); err == nil {
+		t.Errorf("%T.Hash() with explicit %s type; got nil error; want non-nil", td, eip712DomainType)
+	}
+}
@@ -0,0 +1,95 @@
+package eth
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// GenerateMessage mirrors SignatureChecker.generateMessage(data), returning the
+// EIP-191 personal-message hash of data. This is the value used as the key of
+// the usedMessages mapping for single-use signatures.
+func GenerateMessage(data []byte) common.Hash {
+	return crypto.Keccak256Hash(WithPersonalMessagePrefix(data))
+}
+
+// A Voucher carries a signature accepted by SignatureChecker.sol, along with
+// the arguments to be passed to the contract. Data, Nonce (if used) and
+// Signature are ABI-ready for use with abigen bindings.
+type Voucher struct {
+	// Data is the `bytes data` argument of requireValidSignature(). If the
+	// Voucher was created with a nonce then the contract is expected to
+	// compute abi.encodePacked(data, nonce), and Data excludes the nonce.
+	Data []byte
+	// Nonce is only non-zero if the Voucher was created with a nonce.
+	Nonce [32]byte
+	// Message is generateMessage() of the fully packed data, including the
+	// nonce if present.
+	Message   common.Hash
+	Signature []byte
+}
+
+// Signer returns the address of the account that signed the Voucher, using the
+// same rules as SignatureChecker.sol.
+func (v *Voucher) Signer() (common.Address, error) {
+	return RecoverPersonal(v.packed(), v.Signature)
+}
+
+// packed returns the data from which v.Message was generated.
+func (v *Voucher) packed() []byte {
+	if v.Nonce == ([32]byte{}) {
+		return v.Data
+	}
+	buf := make([]byte, 0, len(v.Data)+len(v.Nonce))
+	buf = append(buf, v.Data...)
+	return append(buf, v.Nonce[:]...)
+}
+
+// SignVoucher signs EncodePacked(packed...) for use with the
+// requireValidSignature(signers, data, signature) overload of
+// SignatureChecker.sol, where data = abi.encodePacked(packed...).
+func (s *Signer) SignVoucher(packed ...interface{}) (*Voucher, error) {
+	data, err := EncodePacked(packed...)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.PersonalSign(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Voucher{
+		Data:      data,
+		Message:   GenerateMessage(data),
+		Signature: sig,
+	}, nil
+}
+
+// SignVoucherWithNonce is equivalent to SignVoucher() except that a random
+// nonce is appended to the packed data before signing, as with
+// s.PersonalSignWithNonce(). It is intended for single-use signatures, checked
+// with the requireValidSignature(signers, data, signature, usedMessages)
+// overload of SignatureChecker.sol, where data = abi.encodePacked(<packed...>,
+// nonce).
+func (s *Signer) SignVoucherWithNonce(packed ...interface{}) (*Voucher, error) {
+	data, err := EncodePacked(packed...)
+	if err != nil {
+		return nil, err
+	}
+	sig, nonce, err := s.PersonalSignWithNonce(data)
+	if err != nil {
+		return nil, err
+	}
+	v := &Voucher{
+		Data:      data,
+		Nonce:     nonce,
+		Signature: sig,
+	}
+	v.Message = GenerateMessage(v.packed())
+	return v, nil
+}
+
+// SignAddressVoucher signs addr for use with the requireValidSignature(signers,
+// address, signature) overload of SignatureChecker.sol, which is typically
+// called with msg.sender.
+func (s *Signer) SignAddressVoucher(addr common.Address) (*Voucher, error) {
+	return s.SignVoucher(addr)
+}
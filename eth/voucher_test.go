@@ -0,0 +1,167 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+)
+
+func TestEncodePacked(t *testing.T) {
+	addr := common.HexToAddress("0x71e059FA4594b69200541A189010188eDFFbC34D")
+
+	tests := []struct {
+		name           string
+		args           []interface{}
+		want           string
+		errDiffAgainst interface{}
+	}{
+		{
+			name: "address",
+			args: []interface{}{addr},
+			want: "71e059fa4594b69200541a189010188edffbc34d",
+		},
+		{
+			name: "address pointer",
+			args: []interface{}{&addr},
+			want: "71e059fa4594b69200541a189010188edffbc34d",
+		},
+		{
+			name: "uints of different widths",
+			args: []interface{}{uint8(1), uint16(2), uint32(3), uint64(4)},
+			want: "01" + "0002" + "00000003" + "0000000000000004",
+		},
+		{
+			name: "negative ints",
+			args: []interface{}{int8(-1), int16(-2)},
+			want: "ff" + "fffe",
+		},
+		{
+			name: "uint256",
+			args: []interface{}{big.NewInt(42)},
+			want: "000000000000000000000000000000000000000000000000000000000000002a",
+		},
+		{
+			name: "bytes, string and bool",
+			args: []interface{}{[]byte{0xde, 0xad}, "hi", true, false},
+			want: "dead" + "6869" + "01" + "00",
+		},
+		{
+			name: "fixed-size bytes",
+			args: []interface{}{[4]byte{1, 2, 3, 4}, common.Hash{31: 1}},
+			want: "01020304" + "0000000000000000000000000000000000000000000000000000000000000001",
+		},
+		{
+			name:           "negative uint256",
+			args:           []interface{}{big.NewInt(-1)},
+			errDiffAgainst: "out of uint256 range",
+		},
+		{
+			name:           "unsupported",
+			args:           []interface{}{[]common.Address{addr}},
+			errDiffAgainst: "unsupported type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodePacked(tt.args...)
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Fatalf("EncodePacked(%v) %s", tt.args, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(common.FromHex(tt.want), got); diff != "" {
+				t.Errorf("EncodePacked(%v) diff (-want +got):\n%s", tt.args, diff)
+			}
+		})
+	}
+}
+
+func TestGenerateMessage(t *testing.T) {
+	for _, data := range []string{"", "hello", "world"} {
+		got := GenerateMessage([]byte(data))
+		if want := common.BytesToHash(accounts.TextHash([]byte(data))); got != want {
+			t.Errorf("GenerateMessage(%q) got %v; want %v", data, got, want)
+		}
+	}
+}
+
+func TestVouchers(t *testing.T) {
+	signer, err := NewSigner(256)
+	if err != nil {
+		t.Fatalf("NewSigner(256) error %v", err)
+	}
+	addr := common.HexToAddress("0x71e059FA4594b69200541A189010188eDFFbC34D")
+
+	tests := []struct {
+		name        string
+		sign        func() (*Voucher, error)
+		wantData    []byte
+		wantPacked  func(*Voucher) []byte
+		wantNonZero bool
+	}{
+		{
+			name: "reusable",
+			sign: func() (*Voucher, error) {
+				return signer.SignVoucher(addr, uint8(3))
+			},
+			wantData: append(addr.Bytes(), 3),
+			wantPacked: func(v *Voucher) []byte {
+				return v.Data
+			},
+		},
+		{
+			name: "with nonce",
+			sign: func() (*Voucher, error) {
+				return signer.SignVoucherWithNonce(addr, uint8(3))
+			},
+			wantData: append(addr.Bytes(), 3),
+			wantPacked: func(v *Voucher) []byte {
+				return append(append([]byte{}, v.Data...), v.Nonce[:]...)
+			},
+			wantNonZero: true,
+		},
+		{
+			name: "address",
+			sign: func() (*Voucher, error) {
+				return signer.SignAddressVoucher(addr)
+			},
+			wantData: addr.Bytes(),
+			wantPacked: func(v *Voucher) []byte {
+				return v.Data
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := tt.sign()
+			if err != nil {
+				t.Fatalf("sign() error %v", err)
+			}
+
+			if diff := cmp.Diff(tt.wantData, v.Data); diff != "" {
+				t.Errorf("%T.Data diff (-want +got):\n%s", v, diff)
+			}
+			if got := v.Nonce != [32]byte{}; got != tt.wantNonZero {
+				t.Errorf("%T.Nonce non-zero = %t; want %t", v, got, tt.wantNonZero)
+			}
+			if got, want := v.Message, GenerateMessage(tt.wantPacked(v)); got != want {
+				t.Errorf("%T.Message got %v; want %v", v, got, want)
+			}
+
+			got, err := v.Signer()
+			if err != nil {
+				t.Fatalf("%T.Signer() error %v", v, err)
+			}
+			if want := signer.Address(); got != want {
+				t.Errorf("%T.Signer() got %v; want %v", v, got, want)
+			}
+		})
+	}
+}
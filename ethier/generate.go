@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/divergencetech/ethier/erc721"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	const short = "Generates ERC721 metadata with weighted, rule-constrained and unique trait combinations from a JSON spec; output is JSON Lines on stdout."
+
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: short,
+		Long: short + `
+
+As with the shuffle command, generation is deterministic, being seeded by both the external entropy and a commitment to the spec, so the output can be reproduced by anyone with both.
+
+Each output line is a token's metadata, carrying its token ID in the --id_field field, suitable for use with rarity --input_format jsonl.`,
+		RunE: generate,
+	}
+
+	cmd.Flags().BytesHexP("entropy", "e", nil, "Hexadecimal source of entropy to seed generation")
+	cmd.Flags().String("spec", "", "Path to JSON generation spec; see erc721.GenerationSpec")
+	cmd.Flags().String("id_field", "id", "Field in which to output each token's ID")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// generate implements the `ethier generate` command.
+func generate(cmd *cobra.Command, args []string) error {
+	specPath, err := cmd.Flags().GetString("spec")
+	if err != nil {
+		return err
+	}
+	if specPath == "" {
+		return fmt.Errorf("--spec flag not specified")
+	}
+	idField, err := cmd.Flags().GetString("id_field")
+	if err != nil {
+		return err
+	}
+
+	seed, err := externalEntropy(cmd)
+	if err != nil {
+		return err
+	}
+
+	spec, canonical, err := readGenerationSpec(specPath)
+	if err != nil {
+		return err
+	}
+	seed.hashAndFold(canonical)
+
+	coll, err := spec.Generate(seed.rand())
+	if err != nil {
+		return err
+	}
+	log.Printf("Generated %d tokens", len(coll))
+
+	w := bufio.NewWriter(os.Stdout)
+	if err := writeCollectionJSONL(w, coll, idField); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// readGenerationSpec reads a GenerationSpec from the JSON file, rejecting
+// unknown fields. The returned buffer is the spec's canonical JSON
+// representation, to which generation commits, such that changes in
+// formatting don't change the generated Collection.
+func readGenerationSpec(path string) (*erc721.GenerationSpec, []byte, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("os.ReadFile(%q): %v", path, err)
+	}
+
+	spec := new(erc721.GenerationSpec)
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(spec); err != nil {
+		return nil, nil, fmt.Errorf("json.Decode(%q, %T): %v", path, spec, err)
+	}
+
+	canonical, err := json.Marshal(spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("json.Marshal(%T): %v", spec, err)
+	}
+	return spec, canonical, nil
+}
+
+// writeCollectionJSONL writes each token's Metadata, in order of TokenID, as a
+// line of JSON with the token's ID in the idField field.
+func writeCollectionJSONL(w io.Writer, coll erc721.Collection, idField string) error {
+	ids := make([]erc721.TokenID, 0, len(coll))
+	for id := range coll {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].Cmp(&ids[j]) < 0
+	})
+
+	for _, id := range ids {
+		md := *coll[id]
+		md.Extra = make(map[string]json.RawMessage)
+		for k, v := range coll[id].Extra {
+			md.Extra[k] = v
+		}
+		md.Extra[idField] = json.RawMessage(id.String())
+
+		buf, err := json.Marshal(md)
+		if err != nil {
+			return fmt.Errorf("json.Marshal(%T): %v", md, err)
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/divergencetech/ethier/erc721"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadGenerationSpecCanonical(t *testing.T) {
+	dir := t.TempDir()
+
+	specs := map[string]string{
+		"compact.json": `{"max_supply":2,"traits":[{"trait_type":"a","values":[{"value":"x","weight":1},{"value":"y","weight":1}]}]}`,
+		"pretty.json": `{
+			"traits": [
+				{
+					"values": [
+						{"weight": 1, "value": "x"},
+						{"weight": 1.0, "value": "y"}
+					],
+					"trait_type": "a"
+				}
+			],
+			"max_supply": 2
+		}`,
+	}
+
+	var canonical [][]byte
+	for name, s := range specs {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(s), 0644); err != nil {
+			t.Fatalf("os.WriteFile(%q) error %v", path, err)
+		}
+		_, c, err := readGenerationSpec(path)
+		if err != nil {
+			t.Fatalf("readGenerationSpec(%q) error %v", name, err)
+		}
+		canonical = append(canonical, c)
+	}
+	if !bytes.Equal(canonical[0], canonical[1]) {
+		t.Errorf("readGenerationSpec() returned different canonical representations of equivalent specs: %s and %s", canonical[0], canonical[1])
+	}
+
+	t.Run("unknown field", func(t *testing.T) {
+		path := filepath.Join(dir, "typo.json")
+		if err := os.WriteFile(path, []byte(`{"max_suply":2}`), 0644); err != nil {
+			t.Fatalf("os.WriteFile(%q) error %v", path, err)
+		}
+		if _, _, err := readGenerationSpec(path); err == nil {
+			t.Errorf("readGenerationSpec() with unknown field; got nil error")
+		}
+	})
+}
+
+func TestWriteCollectionJSONL(t *testing.T) {
+	spec := &erc721.GenerationSpec{
+		MaxSupply:    10,
+		FirstTokenID: 1,
+		Traits: []*erc721.TraitSpec{
+			{
+				TraitType: "a",
+				Values: []*erc721.ValueSpec{
+					{Value: "x", Weight: 1},
+					{Value: "y", Weight: 1},
+					{Value: "", Weight: 1},
+				},
+			},
+			{
+				TraitType: "b",
+				Values: []*erc721.ValueSpec{
+					{Value: "1", Weight: 1},
+					{Value: "2", Weight: 1},
+					{Value: "3", Weight: 1},
+					{Value: "4", Weight: 1},
+				},
+			},
+		},
+	}
+
+	e := new(entropy)
+	e.hashAndFold([]byte("seed"))
+	want, err := spec.Generate(e.rand())
+	if err != nil {
+		t.Fatalf("%T.Generate() error %v", spec, err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeCollectionJSONL(&buf, want, "token_id"); err != nil {
+		t.Fatalf("writeCollectionJSONL() error %v", err)
+	}
+	got, err := erc721.ReadCollectionJSONL(&buf, "token_id")
+	if err != nil {
+		t.Fatalf("erc721.ReadCollectionJSONL(writeCollectionJSONL()) error %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("erc721.ReadCollectionJSONL(writeCollectionJSONL()) round trip diff (-want +got):\n%s", diff)
+	}
+
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+
+	"github.com/divergencetech/ethier/merkle"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	const short = "Builds an OpenZeppelin-compatible Merkle-tree allow-list from addresses read from stdin, printing the root, tree and proofs as JSON."
+
+	cmd := &cobra.Command{
+		Use:   "merkle",
+		Short: short,
+		Long: short + `
+
+Each non-empty line of input is an address, optionally followed by a comma and an amount (e.g. a per-address quantity cap); lines without an amount use the --amount flag. The output of ethier shuffle can therefore be piped directly into ethier merkle.
+
+Leaves are hashed as keccak256(bytes.concat(keccak256(abi.encode(address, amount)))), and the "tree" field of the output is in the standard-v1 format of @openzeppelin/merkle-tree.`,
+		RunE: merkleCmd,
+	}
+
+	cmd.Flags().String("amount", "1", "Amount for input lines that don't specify one")
+	cmd.Flags().StringP("output", "o", "", "Output file; defaults to stdout")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// merkleCmd implements the `ethier merkle` command.
+func merkleCmd(cmd *cobra.Command, args []string) error {
+	amtFlag, err := cmd.Flags().GetString("amount")
+	if err != nil {
+		return err
+	}
+	defaultAmount, err := merkle.ParseAmount(amtFlag)
+	if err != nil {
+		return fmt.Errorf("--amount: %v", err)
+	}
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+
+	leaves, err := readMerkleLeaves(os.Stdin, defaultAmount)
+	if err != nil {
+		return err
+	}
+	tree, err := merkle.New(leaves)
+	if err != nil {
+		return err
+	}
+	log.Printf("Merkle root of %d leaves: %v", len(leaves), tree.Root())
+
+	out, err := newMerkleOutput(tree)
+	if err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent(%T): %v", out, err)
+	}
+	buf = append(buf, '\n')
+
+	if output == "" {
+		_, err := os.Stdout.Write(buf)
+		return err
+	}
+	return os.WriteFile(output, buf, 0644)
+}
+
+// readMerkleLeaves parses each non-empty line of r as an address, optionally
+// followed by a comma and an amount. Lines without an amount are assigned
+// defaultAmount.
+func readMerkleLeaves(r io.Reader, defaultAmount *big.Int) ([]merkle.Leaf, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read input: %v", err)
+	}
+
+	var leaves []merkle.Leaf
+	for i, l := range bytes.Split(buf, []byte("\n")) {
+		l = bytes.TrimSpace(l)
+		if len(l) == 0 {
+			continue
+		}
+
+		parts := bytes.SplitN(l, []byte(","), 2)
+		addr := string(bytes.TrimSpace(parts[0]))
+		if !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("line %d: invalid address %q", i+1, addr)
+		}
+
+		amt := new(big.Int).Set(defaultAmount)
+		if len(parts) == 2 {
+			var err error
+			if amt, err = merkle.ParseAmount(string(bytes.TrimSpace(parts[1]))); err != nil {
+				return nil, fmt.Errorf("line %d: %v", i+1, err)
+			}
+		}
+
+		leaves = append(leaves, merkle.Leaf{
+			Address: common.HexToAddress(addr),
+			Amount:  amt,
+		})
+	}
+	return leaves, nil
+}
+
+// A merkleOutput is the output of `ethier merkle`.
+type merkleOutput struct {
+	Root   common.Hash                     `json:"root"`
+	Proofs map[common.Address]*merkleProof `json:"proofs"`
+	Tree   *merkle.Tree                    `json:"tree"`
+}
+
+// A merkleProof is the proof for a single leaf.
+type merkleProof struct {
+	Amount string          `json:"amount"`
+	Proof  []hexutil.Bytes `json:"proof"`
+}
+
+// newMerkleOutput returns the root of the tree, and proofs for all of its
+// leaves.
+func newMerkleOutput(tree *merkle.Tree) (*merkleOutput, error) {
+	out := &merkleOutput{
+		Root:   tree.Root(),
+		Proofs: make(map[common.Address]*merkleProof),
+		Tree:   tree,
+	}
+
+	for i, l := range tree.Leaves() {
+		proof, err := tree.Proof(i)
+		if err != nil {
+			return nil, err
+		}
+		p := &merkleProof{
+			Amount: l.Amount.String(),
+			Proof:  []hexutil.Bytes{},
+		}
+		for _, node := range proof {
+			n := node
+			p.Proof = append(p.Proof, n[:])
+		}
+		out.Proofs[l.Address] = p
+	}
+	return out, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/divergencetech/ethier/merkle"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+)
+
+func TestReadMerkleLeaves(t *testing.T) {
+	alice := common.HexToAddress("0x71e059FA4594b69200541A189010188eDFFbC34D")
+	bob := common.HexToAddress("0x8000B0045d0Ce1265d74FFCF60d4311c565C140B")
+
+	tests := []struct {
+		name           string
+		in             string
+		want           []merkle.Leaf
+		errDiffAgainst interface{}
+	}{
+		{
+			name: "shuffle output",
+			in: `
+			0x71e059FA4594b69200541A189010188eDFFbC34D
+			0x8000B0045d0Ce1265d74FFCF60d4311c565C140B
+			`,
+			want: []merkle.Leaf{
+				{Address: alice, Amount: big.NewInt(1)},
+				{Address: bob, Amount: big.NewInt(1)},
+			},
+		},
+		{
+			name: "explicit amounts",
+			in: `0x71e059FA4594b69200541A189010188eDFFbC34D, 5
+0x8000B0045d0Ce1265d74FFCF60d4311c565C140B`,
+			want: []merkle.Leaf{
+				{Address: alice, Amount: big.NewInt(5)},
+				{Address: bob, Amount: big.NewInt(1)},
+			},
+		},
+		{
+			name: "leading zeros are decimal",
+			in: `0x71e059FA4594b69200541A189010188eDFFbC34D, 010
+0x8000B0045d0Ce1265d74FFCF60d4311c565C140B, 0x10`,
+			want: []merkle.Leaf{
+				{Address: alice, Amount: big.NewInt(10)},
+				{Address: bob, Amount: big.NewInt(16)},
+			},
+		},
+		{
+			name:           "underscores",
+			in:             "0x71e059FA4594b69200541A189010188eDFFbC34D, 1_000",
+			errDiffAgainst: "invalid amount",
+		},
+		{
+			name:           "negative amount",
+			in:             "0x71e059FA4594b69200541A189010188eDFFbC34D, -1",
+			errDiffAgainst: "invalid amount",
+		},
+		{
+			name:           "invalid address",
+			in:             "0xdeadbeef",
+			errDiffAgainst: "invalid address",
+		},
+		{
+			name:           "invalid amount",
+			in:             "0x71e059FA4594b69200541A189010188eDFFbC34D,five",
+			errDiffAgainst: "invalid amount",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readMerkleLeaves(strings.NewReader(tt.in), big.NewInt(1))
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Fatalf("readMerkleLeaves(%q) %s", tt.in, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.Comparer(func(a, b *big.Int) bool { return a.Cmp(b) == 0 })); diff != "" {
+				t.Errorf("readMerkleLeaves(%q) diff (-want +got):\n%s", tt.in, diff)
+			}
+		})
+	}
+}
+
+func TestMerkleOutputProofs(t *testing.T) {
+	var leaves []merkle.Leaf
+	for i := int64(1); i <= 5; i++ {
+		leaves = append(leaves, merkle.Leaf{
+			Address: common.BigToAddress(big.NewInt(i)),
+			Amount:  big.NewInt(i),
+		})
+	}
+	tree, err := merkle.New(leaves)
+	if err != nil {
+		t.Fatalf("merkle.New() error %v", err)
+	}
+
+	out, err := newMerkleOutput(tree)
+	if err != nil {
+		t.Fatalf("newMerkleOutput() error %v", err)
+	}
+
+	for _, l := range leaves {
+		p, ok := out.Proofs[l.Address]
+		if !ok {
+			t.Fatalf("newMerkleOutput() missing proof for %v", l.Address)
+		}
+		var proof [][32]byte
+		for _, node := range p.Proof {
+			proof = append(proof, common.BytesToHash(node))
+		}
+		if !merkle.Verify(out.Root, l, proof) {
+			t.Errorf("merkle.Verify([root], [leaf %v], [output proof]) got false; want true", l.Address)
+		}
+	}
+}
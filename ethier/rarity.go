@@ -3,10 +3,9 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"io"
-	"log"
 	"os"
 	"sort"
+	"strings"
 
 	"github.com/divergencetech/ethier/erc721"
 	"github.com/spf13/cobra"
@@ -15,48 +14,139 @@ import (
 func init() {
 	cmd := &cobra.Command{
 		Use:   "rarity",
-		Short: `Calculates information-theoretic ERC721 metadata rarity, reading a JSON list from stdin, and printing ranked rarity scores on stdout.`,
+		Short: `Calculates ERC721 metadata rarity (information-theoretic by default), reading metadata from stdin or a directory, and printing ranked rarity scores on stdout.`,
 		RunE:  rarity,
 	}
 
+	cmd.Flags().StringP("model", "m", "information", fmt.Sprintf("Rarity model; one of %s", strings.Join(rarityModelNames(), ", ")))
+	cmd.Flags().StringP("format", "f", "text", "Output format; one of text (id and score per line), json (full report), csv (per-token breakdown) or traits-csv (trait frequency tables)")
+	cmd.Flags().String("input_format", "json", "Format of metadata read from stdin; json (array) or jsonl (one object per line)")
+	cmd.Flags().String("id_field", "", "Metadata field carrying each token's ID, as a number or decimal/0x-hex string; required for jsonl input; if empty for json input, token IDs are array indices")
+	cmd.Flags().String("dir", "", "Directory of per-token JSON files, named by decimal or 0x-hex token ID, to read instead of stdin")
+	const bucketerSyntax = "exact, bool, width:<width>[:<origin>] or quantile:<n>"
+	cmd.Flags().String("numeric_buckets", erc721.DefaultNumericBuckets, "Bucketing of number, boost_number and boost_percentage attributes; "+bucketerSyntax)
+	cmd.Flags().String("date_buckets", erc721.DefaultDateBuckets, "Bucketing of date attributes; "+bucketerSyntax)
+	cmd.Flags().String("default_buckets", erc721.DefaultBuckets, "Bucketing of non-string attributes without a display type, including booleans; "+bucketerSyntax)
+
 	rootCmd.AddCommand(cmd)
 }
 
+// rarityModelNames returns the sorted keys of erc721.RarityModels.
+func rarityModelNames() []string {
+	var names []string
+	for n := range erc721.RarityModels {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func rarity(cmd *cobra.Command, args []string) error {
-	buf, err := io.ReadAll(os.Stdin)
+	modelName, err := cmd.Flags().GetString("model")
 	if err != nil {
-		return fmt.Errorf("io.ReadAll(stdin): %v", err)
+		return err
+	}
+	model, ok := erc721.RarityModels[modelName]
+	if !ok {
+		return fmt.Errorf("unknown --model %q; must be one of %s", modelName, strings.Join(rarityModelNames(), ", "))
 	}
 
-	var md []*erc721.Metadata
-	if err := json.Unmarshal(buf, &md); err != nil {
-		return fmt.Errorf("json.Unmarshal(stdin, %T): %v", md, err)
+	coll, err := readCollection(cmd)
+	if err != nil {
+		return err
 	}
 
-	type pair struct {
-		id    erc721.TokenID
-		score float64
+	bucketing, err := bucketingFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	traits, err := coll.BucketedTraits(bucketing)
+	if err != nil {
+		return err
 	}
-	var scores []pair
-	res := erc721.CollectionFromMetadata(md).Rarity(func(x interface{}) string {
-		log.Fatalf("Non-string attribute of type %T not supported by CLI", x)
-		return ""
-	})
 
-	for id, score := range res.Scores {
-		scores = append(scores, pair{id, score})
+	ranked := erc721.RankRarity(model.Scores(traits))
+
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
 	}
-	sort.Slice(scores, func(i, j int) bool {
-		sI, sJ := scores[i], scores[j]
-		if scI, scJ := sI.score, sJ.score; scI != scJ {
-			return scI > scJ
+	report := erc721.NewRarityReport(erc721.EffectiveTraits(model, traits), ranked)
+	report.Model = modelName
+
+	switch format {
+	case "text":
+		fmt.Fprintf(os.Stderr, "Collection entropy: %.4f\n", report.Entropy)
+		for _, r := range ranked {
+			fmt.Printf("%s %.4f\n", r.ID.String(), r.Score)
 		}
-		return sI.id.Cmp(&sJ.id) == -1
-	})
+		return nil
+	case "json":
+		return report.WriteJSON(os.Stdout)
+	case "csv":
+		return report.WriteTokensCSV(os.Stdout)
+	case "traits-csv":
+		return report.WriteTraitsCSV(os.Stdout)
+	default:
+		return fmt.Errorf("unknown --format %q; must be text, json, csv or traits-csv", format)
+	}
+}
 
-	fmt.Fprintf(os.Stderr, "Collection entropy: %.4f\n", res.Entropy)
-	for _, s := range scores {
-		fmt.Printf("%s %.4f\n", s.id.String(), s.score)
+// readCollection reads the Collection from the source specified by the
+// Command's input flags.
+func readCollection(cmd *cobra.Command) (erc721.Collection, error) {
+	dir, err := cmd.Flags().GetString("dir")
+	if err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		return erc721.LoadCollectionDir(dir)
+	}
+
+	format, err := cmd.Flags().GetString("input_format")
+	if err != nil {
+		return nil, err
+	}
+	idField, err := cmd.Flags().GetString("id_field")
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case "json":
+		if idField != "" {
+			return erc721.ReadCollectionJSON(os.Stdin, idField)
+		}
+		var md []*erc721.Metadata
+		if err := json.NewDecoder(os.Stdin).Decode(&md); err != nil {
+			return nil, fmt.Errorf("json.Decode(stdin, %T): %v", md, err)
+		}
+		return erc721.CollectionFromMetadata(md), nil
+	case "jsonl":
+		if idField == "" {
+			return nil, fmt.Errorf("--id_field required for jsonl input")
+		}
+		return erc721.ReadCollectionJSONL(os.Stdin, idField)
+	default:
+		return nil, fmt.Errorf("unknown --input_format %q; must be json or jsonl", format)
+	}
+}
+
+// bucketingFromFlags parses the Command's bucketing flags.
+func bucketingFromFlags(cmd *cobra.Command) (*erc721.Bucketing, error) {
+	b := new(erc721.Bucketing)
+	for flag, dst := range map[string]*erc721.Bucketer{
+		"numeric_buckets": &b.Numeric,
+		"date_buckets":    &b.Date,
+		"default_buckets": &b.Default,
+	} {
+		s, err := cmd.Flags().GetString(flag)
+		if err != nil {
+			return nil, err
+		}
+		if *dst, err = erc721.ParseBucketer(s); err != nil {
+			return nil, fmt.Errorf("--%s: %v", flag, err)
+		}
 	}
-	return nil
+	return b, nil
 }
@@ -0,0 +1,426 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/prf"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	const short = "Signs allow-list entries read from stdin, producing JSON signatures compatible with SignatureChecker.sol."
+
+	cmd := &cobra.Command{
+		Use:   "sign",
+		Short: short,
+		Long: short + `
+
+Each entry is an address, optionally followed by extra data such as a quantity cap, the Solidity types of which are specified with --types. The signed message is abi.encodePacked(address, data..., nonce) unless --reusable is set, in which case the nonce is omitted. Entries without extra data signed with --reusable are therefore compatible with requireValidSignature(signers, msg.sender, signature).
+
+Input is either CSV, with one entry per line and an optional header row with "address" as its first column, or a JSON array with each element being an address string or an object of the form {"address": "0x…", "data": […]}.
+
+Exactly one source of the private key must be provided.`,
+		RunE: sign,
+	}
+
+	fs := cmd.Flags()
+	fs.String("format", "", "Input format, csv or json; inferred from the input if empty")
+	fs.StringSlice("types", nil, "Solidity types of per-address data fields, following the address; one of address, bool, string, bytes, bytes32 or uint<N> for N a multiple of 8 up to 256 (e.g. uint16). Integers are decimal unless 0x-prefixed")
+	fs.Bool("reusable", false, "Omit the random nonce, allowing signatures to be reused")
+	fs.StringP("output", "o", "", "Output file; defaults to stdout")
+
+	fs.String("mnemonic_file", "", "File containing a BIP39 mnemonic from which to derive the signer")
+	fs.String("hd_path_prefix", string(eth.DefaultHDPathPrefix), "HD path prefix used with --mnemonic_file or --prf_keyset")
+	fs.Uint("account", 0, "HD account number used with --mnemonic_file or --prf_keyset")
+	fs.String("keystore", "", "Encrypted JSON keystore file from which to load the signer")
+	fs.String("passphrase_file", "", "File containing the passphrase for --keystore or the password for --mnemonic_file")
+	fs.String("prf_keyset", "", "Cleartext JSON Tink PRF keyset from which to derive the signer; see eth.SignerFromPRF()")
+	fs.BytesHex("prf_input", nil, "Hexadecimal input to the PRF used with --prf_keyset")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// sign implements the `ethier sign` command.
+func sign(cmd *cobra.Command, args []string) error {
+	fs := cmd.Flags()
+	format, err := fs.GetString("format")
+	if err != nil {
+		return err
+	}
+	types, err := fs.GetStringSlice("types")
+	if err != nil {
+		return err
+	}
+	reusable, err := fs.GetBool("reusable")
+	if err != nil {
+		return err
+	}
+	output, err := fs.GetString("output")
+	if err != nil {
+		return err
+	}
+
+	signer, err := signerFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	log.Printf("Signing with %v", signer)
+
+	entries, err := readAllowList(os.Stdin, format, types)
+	if err != nil {
+		return err
+	}
+	out, err := signAllowList(signer, entries, types, reusable)
+	if err != nil {
+		return err
+	}
+	log.Printf("Signed %d entries", len(entries))
+
+	buf, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent(%T): %v", out, err)
+	}
+	buf = append(buf, '\n')
+
+	if output == "" {
+		_, err := os.Stdout.Write(buf)
+		return err
+	}
+	return os.WriteFile(output, buf, 0644)
+}
+
+// signerFromFlags returns the Signer defined by the Command's key-source flags,
+// exactly one of which must be set.
+func signerFromFlags(cmd *cobra.Command) (*eth.Signer, error) {
+	fs := cmd.Flags()
+	var (
+		sources = make(map[string]string)
+		flags   = []string{"mnemonic_file", "keystore", "prf_keyset"}
+	)
+	for _, f := range flags {
+		v, err := fs.GetString(f)
+		if err != nil {
+			return nil, err
+		}
+		if v != "" {
+			sources[f] = v
+		}
+	}
+	if len(sources) != 1 {
+		return nil, fmt.Errorf("exactly one of --%s must be set", strings.Join(flags, ", --"))
+	}
+
+	prefix, err := fs.GetString("hd_path_prefix")
+	if err != nil {
+		return nil, err
+	}
+	hdp := eth.HDPathPrefix(prefix)
+	account, err := fs.GetUint("account")
+	if err != nil {
+		return nil, err
+	}
+
+	var passphrase string
+	if f, err := fs.GetString("passphrase_file"); err != nil {
+		return nil, err
+	} else if f != "" {
+		buf, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("read --passphrase_file: %v", err)
+		}
+		passphrase = strings.TrimRight(string(buf), "\r\n")
+	}
+
+	switch {
+	case sources["mnemonic_file"] != "":
+		buf, err := os.ReadFile(sources["mnemonic_file"])
+		if err != nil {
+			return nil, fmt.Errorf("read --mnemonic_file: %v", err)
+		}
+		return hdp.SignerFromSeedPhrase(strings.TrimSpace(string(buf)), passphrase, account)
+
+	case sources["keystore"] != "":
+		return eth.SignerFromKeystoreFile(sources["keystore"], passphrase)
+
+	default:
+		input, err := fs.GetBytesHex("prf_input")
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.Open(sources["prf_keyset"])
+		if err != nil {
+			return nil, fmt.Errorf("open --prf_keyset: %v", err)
+		}
+		defer f.Close()
+
+		kh, err := insecurecleartextkeyset.Read(keyset.NewJSONReader(f))
+		if err != nil {
+			return nil, fmt.Errorf("read --prf_keyset: %v", err)
+		}
+		set, err := prf.NewPRFSet(kh)
+		if err != nil {
+			return nil, fmt.Errorf("prf.NewPRFSet(): %v", err)
+		}
+		return hdp.SignerFromPRFSet(set, input, account)
+	}
+}
+
+// An allowListEntry is a single address to be signed, along with the raw
+// values of its extra data.
+type allowListEntry struct {
+	Address common.Address
+	Data    []string
+}
+
+// UnmarshalJSON accepts either an address string or an object with address
+// and data fields, the latter of which may contain strings, numbers or
+// booleans.
+func (e *allowListEntry) UnmarshalJSON(buf []byte) error {
+	if err := json.Unmarshal(buf, &e.Address); err == nil {
+		return nil
+	}
+
+	var obj struct {
+		Address *common.Address `json:"address"`
+		Data    []interface{}   `json:"data"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil {
+		return err
+	}
+	if obj.Address == nil {
+		return fmt.Errorf("missing address in %s", buf)
+	}
+
+	e.Address = *obj.Address
+	for _, d := range obj.Data {
+		e.Data = append(e.Data, fmt.Sprint(d))
+	}
+	return nil
+}
+
+// csvAddressHeader is the (case-insensitive) first column of an optional CSV
+// header row. Any other invalid address in the first row is an error, not a
+// header, so a typo in the first entry can't silently drop it.
+const csvAddressHeader = "address"
+
+// readAllowList parses all entries from r, in either csv or json format. If
+// format is empty then it is inferred from the first non-whitespace character.
+// Each entry must have exactly len(types) data fields.
+func readAllowList(r io.Reader, format string, types []string) ([]allowListEntry, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read input: %v", err)
+	}
+	if format == "" {
+		format = "csv"
+		if t := bytes.TrimSpace(buf); len(t) > 0 && t[0] == '[' {
+			format = "json"
+		}
+	}
+
+	var entries []allowListEntry
+	switch format {
+	case "json":
+		if err := json.Unmarshal(buf, &entries); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal(input, %T): %v", entries, err)
+		}
+
+	case "csv":
+		rd := csv.NewReader(bytes.NewReader(buf))
+		rd.FieldsPerRecord = -1
+		rd.TrimLeadingSpace = true
+		records, err := rd.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("read CSV: %v", err)
+		}
+		for i, rec := range records {
+			addr := strings.TrimSpace(rec[0])
+			if i == 0 && strings.EqualFold(addr, csvAddressHeader) {
+				continue
+			}
+			if !common.IsHexAddress(addr) {
+				return nil, fmt.Errorf("CSV line %d: invalid address %q", i+1, addr)
+			}
+			entries = append(entries, allowListEntry{
+				Address: common.HexToAddress(addr),
+				Data:    rec[1:],
+			})
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+
+	seen := make(map[common.Address]bool)
+	for i, e := range entries {
+		if n := len(e.Data); n != len(types) {
+			return nil, fmt.Errorf("entry %d (%v) has %d data fields; want %d to match --types", i, e.Address, n, len(types))
+		}
+		if seen[e.Address] {
+			return nil, fmt.Errorf("duplicate address %v", e.Address)
+		}
+		seen[e.Address] = true
+	}
+	return entries, nil
+}
+
+// A signedAllowList is the output of `ethier sign`.
+type signedAllowList struct {
+	Signer   common.Address                   `json:"signer"`
+	Types    []string                         `json:"types"`
+	Reusable bool                             `json:"reusable"`
+	Vouchers map[common.Address]*signedTicket `json:"vouchers"`
+}
+
+// A signedTicket is a single, signed allow-list entry.
+type signedTicket struct {
+	Data      []string      `json:"data,omitempty"`
+	Packed    hexutil.Bytes `json:"packed"`
+	Nonce     hexutil.Bytes `json:"nonce,omitempty"`
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// signAllowList signs every entry, packing its address and data fields as
+// abi.encodePacked(address, data...), with data values parsed according to
+// types.
+func signAllowList(s *eth.Signer, entries []allowListEntry, types []string, reusable bool) (*signedAllowList, error) {
+	out := &signedAllowList{
+		Signer:   s.Address(),
+		Types:    append([]string{"address"}, types...),
+		Reusable: reusable,
+		Vouchers: make(map[common.Address]*signedTicket),
+	}
+
+	for _, e := range entries {
+		packed := []interface{}{e.Address}
+		for i, d := range e.Data {
+			v, err := parseSolidityValue(types[i], d)
+			if err != nil {
+				return nil, fmt.Errorf("%v data field %d: %v", e.Address, i, err)
+			}
+			packed = append(packed, v)
+		}
+
+		var (
+			v   *eth.Voucher
+			err error
+		)
+		if reusable {
+			v, err = s.SignVoucher(packed...)
+		} else {
+			v, err = s.SignVoucherWithNonce(packed...)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("sign %v: %v", e.Address, err)
+		}
+
+		t := &signedTicket{
+			Data:      e.Data,
+			Packed:    v.Data,
+			Signature: v.Signature,
+		}
+		if !reusable {
+			t.Nonce = v.Nonce[:]
+		}
+		out.Vouchers[e.Address] = t
+	}
+	return out, nil
+}
+
+// parseSolidityValue parses s as the Solidity type, returning a value
+// appropriate for use with eth.EncodePacked().
+func parseSolidityValue(typ, s string) (interface{}, error) {
+	s = strings.TrimSpace(s)
+
+	switch typ {
+	case "address":
+		if !common.IsHexAddress(s) {
+			return nil, fmt.Errorf("invalid address %q", s)
+		}
+		return common.HexToAddress(s), nil
+	case "bool":
+		return strconv.ParseBool(s)
+	case "string":
+		return s, nil
+	case "bytes":
+		return hexutil.Decode(s)
+	case "bytes32":
+		b, err := hexutil.Decode(s)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != 32 {
+			return nil, fmt.Errorf("%q is %d bytes; want 32", s, len(b))
+		}
+		return common.BytesToHash(b), nil
+	case "uint":
+		typ = "uint256"
+	}
+
+	if strings.HasPrefix(typ, "uint") {
+		bits, err := strconv.Atoi(strings.TrimPrefix(typ, "uint"))
+		if err != nil || bits < 8 || bits > 256 || bits%8 != 0 {
+			return nil, fmt.Errorf("unsupported type %q", typ)
+		}
+		n, err := parseUint(s)
+		if err != nil {
+			return nil, err
+		}
+		if n.BitLen() > bits {
+			return nil, fmt.Errorf("%q out of range for %s", s, typ)
+		}
+
+		switch bits {
+		case 8:
+			return uint8(n.Uint64()), nil
+		case 16:
+			return uint16(n.Uint64()), nil
+		case 32:
+			return uint32(n.Uint64()), nil
+		case 64:
+			return n.Uint64(), nil
+		case 256:
+			return n, nil
+		default:
+			// eth.EncodePacked() has no native equivalent of other widths, but
+			// packs byte slices verbatim.
+			return n.FillBytes(make([]byte, bits/8)), nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported type %q", typ)
+}
+
+// parseUint parses s as a non-negative decimal integer, or as hexadecimal if
+// it has a 0x prefix. Unlike base-0 parsing, a leading zero does NOT denote
+// octal, as allow-list amounts such as "010" are intended to be decimal.
+func parseUint(s string) (*big.Int, error) {
+	digits, base := s, 10
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		digits, base = s[2:], 16
+	}
+	// SetString() accepts signs, which are invalid for unsigned types.
+	if digits == "" || digits[0] == '-' || digits[0] == '+' {
+		return nil, fmt.Errorf("invalid unsigned integer %q", s)
+	}
+	n, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return nil, fmt.Errorf("invalid unsigned integer %q", s)
+	}
+	return n, nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+)
+
+func TestReadAllowList(t *testing.T) {
+	alice := common.HexToAddress("0x71e059FA4594b69200541A189010188eDFFbC34D")
+	bob := common.HexToAddress("0x8000B0045d0Ce1265d74FFCF60d4311c565C140B")
+
+	tests := []struct {
+		name           string
+		in             string
+		format         string
+		types          []string
+		want           []allowListEntry
+		errDiffAgainst interface{}
+	}{
+		{
+			name: "CSV addresses only",
+			in: `0x71e059FA4594b69200541A189010188eDFFbC34D
+0x8000B0045d0Ce1265d74FFCF60d4311c565C140B`,
+			want: []allowListEntry{
+				{Address: alice, Data: []string{}},
+				{Address: bob, Data: []string{}},
+			},
+		},
+		{
+			name: "CSV with header and data",
+			in: `address, cap
+0x71e059FA4594b69200541A189010188eDFFbC34D, 3
+0x8000B0045d0Ce1265d74FFCF60d4311c565C140B, 5`,
+			types: []string{"uint16"},
+			want: []allowListEntry{
+				{Address: alice, Data: []string{"3"}},
+				{Address: bob, Data: []string{"5"}},
+			},
+		},
+		{
+			name:   "JSON mixed elements",
+			in:     `["0x71e059FA4594b69200541A189010188eDFFbC34D", {"address": "0x8000B0045d0Ce1265d74FFCF60d4311c565C140B"}]`,
+			format: "json",
+			want: []allowListEntry{
+				{Address: alice},
+				{Address: bob},
+			},
+		},
+		{
+			name:   "JSON numeric and string data",
+			in:     `[{"address": "0x8000B0045d0Ce1265d74FFCF60d4311c565C140B", "data": [5, "0x06"]}]`,
+			format: "json",
+			types:  []string{"uint8", "uint8"},
+			want: []allowListEntry{
+				{Address: bob, Data: []string{"5", "0x06"}},
+			},
+		},
+		{
+			name:           "incorrect number of data fields",
+			in:             `["0x71e059FA4594b69200541A189010188eDFFbC34D"]`,
+			types:          []string{"uint8"},
+			errDiffAgainst: "has 0 data fields; want 1",
+		},
+		{
+			name:  "JSON inferred",
+			in:    ` [{"address": "0x8000B0045d0Ce1265d74FFCF60d4311c565C140B", "data": [5]}]`,
+			types: []string{"uint8"},
+			want: []allowListEntry{
+				{Address: bob, Data: []string{"5"}},
+			},
+		},
+		{
+			name: "duplicate address",
+			in: `0x71e059FA4594b69200541A189010188eDFFbC34D
+0x71e059fa4594b69200541a189010188edffbc34d`,
+			errDiffAgainst: "duplicate address",
+		},
+		{
+			name: "CSV header with capitalised address column",
+			in: `Address
+0x71e059FA4594b69200541A189010188eDFFbC34D`,
+			want: []allowListEntry{
+				{Address: alice, Data: []string{}},
+			},
+		},
+		{
+			name: "invalid first address not treated as header",
+			in: `0x71e059FA4594b69200541A189010188eDFFbC3
+0x8000B0045d0Ce1265d74FFCF60d4311c565C140B`,
+			errDiffAgainst: "CSV line 1: invalid address",
+		},
+		{
+			name: "unknown header",
+			in: `wallet, cap
+0x71e059FA4594b69200541A189010188eDFFbC34D, 3`,
+			types:          []string{"uint16"},
+			errDiffAgainst: "CSV line 1: invalid address",
+		},
+		{
+			name: "invalid address",
+			in: `0x71e059FA4594b69200541A189010188eDFFbC34D
+0xdeadbeef`,
+			errDiffAgainst: "invalid address",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readAllowList(strings.NewReader(tt.in), tt.format, tt.types)
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Fatalf("readAllowList(%q, %q, %q) %s", tt.in, tt.format, tt.types, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("readAllowList(%q, %q, %q) diff (-want +got):\n%s", tt.in, tt.format, tt.types, diff)
+			}
+		})
+	}
+}
+
+func TestParseSolidityValue(t *testing.T) {
+	tests := []struct {
+		typ, in        string
+		want           interface{}
+		errDiffAgainst interface{}
+	}{
+		{typ: "uint8", in: "255", want: uint8(255)},
+		{typ: "uint8", in: "256", errDiffAgainst: "out of range"},
+		{typ: "uint16", in: "0x10", want: uint16(16)},
+		{typ: "uint64", in: "42", want: uint64(42)},
+		{typ: "uint256", in: "1000000000000000000000", want: new(big.Int).Mul(big.NewInt(1e18), big.NewInt(1000))},
+		{typ: "bool", in: "true", want: true},
+		{typ: "string", in: " hello ", want: "hello"},
+		{typ: "address", in: "0x71e059FA4594b69200541A189010188eDFFbC34D", want: common.HexToAddress("0x71e059FA4594b69200541A189010188eDFFbC34D")},
+		{typ: "uint16", in: "010", want: uint16(10)},
+		{typ: "uint256", in: "010", want: big.NewInt(10)},
+		{typ: "uint", in: "0xff", want: big.NewInt(255)},
+		{typ: "uint256", in: "-5", errDiffAgainst: "invalid unsigned integer"},
+		{typ: "uint8", in: "-1", errDiffAgainst: "invalid unsigned integer"},
+		{typ: "uint32", in: "+1", errDiffAgainst: "invalid unsigned integer"},
+		{typ: "uint64", in: "0x", errDiffAgainst: "invalid unsigned integer"},
+		{typ: "uint64", in: "1.5", errDiffAgainst: "invalid unsigned integer"},
+		{typ: "uint24", in: "0x010203", want: []byte{1, 2, 3}},
+		{typ: "uint24", in: "1", want: []byte{0, 0, 1}},
+		{typ: "uint24", in: "16777216", errDiffAgainst: "out of range"},
+		{typ: "uint128", in: "1", want: append(make([]byte, 15), 1)},
+		{typ: "uint256", in: "0x1" + strings.Repeat("0", 64), errDiffAgainst: "out of range"},
+		{typ: "uint7", in: "1", errDiffAgainst: "unsupported type"},
+		{typ: "uint264", in: "1", errDiffAgainst: "unsupported type"},
+		{typ: "int8", in: "1", errDiffAgainst: "unsupported type"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSolidityValue(tt.typ, tt.in)
+		if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+			t.Errorf("parseSolidityValue(%q, %q) %s", tt.typ, tt.in, diff)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if diff := cmp.Diff(tt.want, got, cmp.Comparer(func(a, b *big.Int) bool { return a.Cmp(b) == 0 })); diff != "" {
+			t.Errorf("parseSolidityValue(%q, %q) diff (-want +got):\n%s", tt.typ, tt.in, diff)
+		}
+	}
+}
+
+func TestSignAllowList(t *testing.T) {
+	signer, err := eth.NewSigner(256)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(256) error %v", err)
+	}
+
+	addr := common.HexToAddress("0x71e059FA4594b69200541A189010188eDFFbC34D")
+	entries := []allowListEntry{{Address: addr, Data: []string{"3"}}}
+	types := []string{"uint16"}
+
+	for _, reusable := range []bool{true, false} {
+		got, err := signAllowList(signer, entries, types, reusable)
+		if err != nil {
+			t.Fatalf("signAllowList(…, reusable = %t) error %v", reusable, err)
+		}
+
+		ticket := got.Vouchers[addr]
+		if diff := cmp.Diff(append(addr.Bytes(), 0, 3), []byte(ticket.Packed)); diff != "" {
+			t.Errorf("signAllowList(…, reusable = %t) packed data diff (-want +got):\n%s", reusable, diff)
+		}
+
+		msg := append([]byte{}, ticket.Packed...)
+		if reusable {
+			if len(ticket.Nonce) != 0 {
+				t.Errorf("signAllowList(…, reusable = true) got nonce %#x; want none", ticket.Nonce)
+			}
+		} else {
+			if len(ticket.Nonce) != 32 {
+				t.Errorf("signAllowList(…, reusable = false) got nonce %#x; want 32 bytes", ticket.Nonce)
+			}
+			msg = append(msg, ticket.Nonce...)
+		}
+
+		gotSigner, err := eth.RecoverPersonal(msg, ticket.Signature)
+		if err != nil {
+			t.Fatalf("eth.RecoverPersonal() error %v", err)
+		}
+		if want := signer.Address(); gotSigner != want || got.Signer != want {
+			t.Errorf("signAllowList(…, reusable = %t) signed by %v, reporting %v; want %v", reusable, gotSigner, got.Signer, want)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 
+	"github.com/divergencetech/ethier/erc20"
 	"github.com/divergencetech/ethier/eth"
 )
 
@@ -46,10 +47,14 @@ func LogGas(tb testing.TB, tx *types.Transaction, prefix string) {
 }
 
 // Comparers returns `extra`, appended with common comparison Options for
-// cmp.Diff(); e.g. for big.Int,
+// cmp.Diff(); e.g. for big.Int, and erc20.Amount (which are only equal if they
+// are of the same Token).
 func Comparers(extra ...cmp.Option) []cmp.Option {
 	return append(
 		extra,
+		cmp.Comparer(func(a, b erc20.Amount) bool {
+			return a.Equal(b)
+		}),
 		cmp.Comparer(func(a, b *big.Int) bool {
 			switch {
 			case a == nil && b == nil:
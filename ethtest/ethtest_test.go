@@ -3,6 +3,10 @@ package ethtest
 import (
 	"math/big"
 	"testing"
+
+	"github.com/divergencetech/ethier/erc20"
+	"github.com/divergencetech/ethier/eth"
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestFastForward(t *testing.T) {
@@ -40,3 +44,60 @@ func TestFastForward(t *testing.T) {
 		}
 	}
 }
+
+func TestComparers(t *testing.T) {
+	type holding struct {
+		Balance *big.Int
+		Price   erc20.Amount
+		Paid    *erc20.Amount
+	}
+
+	oneWETH := erc20.WETH.Units(1)
+	tests := []struct {
+		name  string
+		a, b  holding
+		equal bool
+	}{
+		{
+			name: "distinct but equal values",
+			a: holding{
+				Balance: big.NewInt(42),
+				Price:   oneWETH,
+				Paid:    &oneWETH,
+			},
+			b: holding{
+				Balance: big.NewInt(42),
+				Price:   erc20.WETH.Amount(eth.Ether(1)),
+				Paid:    &erc20.Amount{Token: erc20.WETH, Value: eth.Ether(1)},
+			},
+			equal: true,
+		},
+		{
+			name:  "different amounts",
+			a:     holding{Price: oneWETH},
+			b:     holding{Price: erc20.WETH.Units(2)},
+			equal: false,
+		},
+		{
+			name:  "different tokens",
+			a:     holding{Price: oneWETH},
+			b:     holding{Price: erc20.ETH.Units(1)},
+			equal: false,
+		},
+		{
+			name:  "nil amount pointer",
+			a:     holding{Paid: &oneWETH},
+			b:     holding{},
+			equal: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := cmp.Diff(tt.a, tt.b, Comparers()...)
+			if got := diff == ""; got != tt.equal {
+				t.Errorf("cmp.Diff(%+v, %+v, Comparers()...) got %q; want equal = %t", tt.a, tt.b, diff, tt.equal)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+// Package ipfs computes IPFS content identifiers (CIDs) locally, without the
+// need for an IPFS node. This allows, for example, the tokenURI base of an NFT
+// collection to be known before its metadata is uploaded.
+//
+// Files and directories are encoded as UnixFS DAGs in the same manner as
+// `ipfs add --cid-version=1`: files are split into 256KiB chunks stored as raw
+// leaves (a single-chunk file is its own raw leaf) and combined in a balanced
+// DAG of at most 174 links per node. Directories are always encoded as a single
+// dag-pb node. IPFS nodes automatically shard large directories (HAMT), which
+// would result in a different CID, so Directory() returns an error for any
+// directory that `ipfs add` would shard.
+package ipfs
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Multicodec codes used in CIDs.
+const (
+	codecRaw    = 0x55
+	codecDAGPB  = 0x70
+	mhSHA256    = 0x12
+	cidVersion1 = 1
+)
+
+// A CID is a version 1 content identifier of a block hashed with SHA2-256.
+type CID struct {
+	codec  uint64
+	digest [sha256.Size]byte
+}
+
+// newCID returns the CID of the block, with the specified codec.
+func newCID(codec uint64, block []byte) CID {
+	return CID{
+		codec:  codec,
+		digest: sha256.Sum256(block),
+	}
+}
+
+// Bytes returns the binary encoding of the CID.
+func (c CID) Bytes() []byte {
+	buf := appendUvarint(nil, cidVersion1)
+	buf = appendUvarint(buf, c.codec)
+	buf = appendUvarint(buf, mhSHA256)
+	buf = appendUvarint(buf, sha256.Size)
+	return append(buf, c.digest[:]...)
+}
+
+// base32Lower is the multibase encoding used by default for v1 CIDs.
+var base32Lower = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// String returns the CID in its canonical, base32 string form; e.g.
+// bafybei....
+func (c CID) String() string {
+	return "b" + base32Lower.EncodeToString(c.Bytes())
+}
+
+// URL returns "ipfs://" + c.String().
+func (c CID) URL() string {
+	return "ipfs://" + c.String()
+}
+
+// A DAG is the root of a UnixFS DAG, carrying the information necessary to link
+// to it from a directory.
+type DAG struct {
+	CID CID
+	// Size is the cumulative size of all blocks in the DAG.
+	Size uint64
+	// fileSize is the size of the file's contents, excluding encoding
+	// overhead.
+	fileSize uint64
+}
+
+// Chunking parameters, as used by default by `ipfs add`.
+const (
+	ChunkSize    = 256 << 10
+	MaxLinks     = 174
+	unixfsDir    = 1
+	unixfsFile   = 2
+	pbLinksField = 2
+	pbDataField  = 1
+)
+
+// File returns the DAG of a file with the contents.
+func File(data []byte) DAG {
+	if len(data) <= ChunkSize {
+		return rawLeaf(data)
+	}
+
+	var layer []DAG
+	for i := 0; i < len(data); i += ChunkSize {
+		end := i + ChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		layer = append(layer, rawLeaf(data[i:end]))
+	}
+
+	for len(layer) > 1 {
+		var next []DAG
+		for i := 0; i < len(layer); i += MaxLinks {
+			end := i + MaxLinks
+			if end > len(layer) {
+				end = len(layer)
+			}
+			next = append(next, fileNode(layer[i:end]))
+		}
+		layer = next
+	}
+	return layer[0]
+}
+
+// rawLeaf returns the DAG of a single raw block.
+func rawLeaf(data []byte) DAG {
+	return DAG{
+		CID:      newCID(codecRaw, data),
+		Size:     uint64(len(data)),
+		fileSize: uint64(len(data)),
+	}
+}
+
+// fileNode returns the DAG of a dag-pb node linking to the children, which
+// together make up a contiguous portion of a file.
+func fileNode(children []DAG) DAG {
+	var (
+		data     []byte
+		fileSize uint64
+		links    []pbLink
+	)
+	data = appendVarintField(data, 1, unixfsFile)
+	for _, c := range children {
+		fileSize += c.fileSize
+	}
+	data = appendVarintField(data, 3, fileSize)
+	for _, c := range children {
+		data = appendVarintField(data, 4, c.fileSize)
+		links = append(links, pbLink{dag: c})
+	}
+
+	return pbNode(links, data, fileSize)
+}
+
+// HAMTShardingSize is the estimated size of a directory node, in bytes, at or
+// above which `ipfs add` shards the directory. The estimate is the sum, over
+// all entries, of the length of the name plus that of the binary CID.
+const HAMTShardingSize = 256 << 10
+
+// Directory returns the DAG of a directory containing the named entries. Names
+// must be non-empty and must not contain a slash. An error is returned if the
+// directory would be sharded by `ipfs add`, as its CID would differ; see
+// HAMTShardingSize.
+func Directory(entries map[string]DAG) (DAG, error) {
+	links := make([]pbLink, 0, len(entries))
+	var estimatedSize int
+	for name, dag := range entries {
+		if name == "" {
+			return DAG{}, errors.New("empty directory-entry name")
+		}
+		if strings.Contains(name, "/") {
+			return DAG{}, fmt.Errorf("directory-entry name %q contains slash", name)
+		}
+		links = append(links, pbLink{name: name, dag: dag})
+		estimatedSize += len(name) + len(dag.CID.Bytes())
+	}
+	if estimatedSize >= HAMTShardingSize {
+		return DAG{}, fmt.Errorf("directory of %d entries would be sharded by IPFS, resulting in a different CID; estimated size %d >= %d", len(entries), estimatedSize, HAMTShardingSize)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].name < links[j].name
+	})
+
+	return pbNode(links, appendVarintField(nil, 1, unixfsDir), 0), nil
+}
+
+// A pbLink is a link in a dag-pb node.
+type pbLink struct {
+	name string
+	dag  DAG
+}
+
+// pbNode returns the DAG of a dag-pb node with the links and data.
+func pbNode(links []pbLink, data []byte, fileSize uint64) DAG {
+	// Canonical dag-pb encoding has links before data, regardless of field
+	// numbers.
+	var (
+		buf  []byte
+		size uint64
+	)
+	for _, l := range links {
+		var link []byte
+		link = appendBytesField(link, 1, l.dag.CID.Bytes())
+		// The name is always included, even if empty, as done by go-merkledag.
+		link = appendBytesField(link, 2, []byte(l.name))
+		link = appendVarintField(link, 3, l.dag.Size)
+
+		buf = appendBytesField(buf, pbLinksField, link)
+		size += l.dag.Size
+	}
+	buf = appendBytesField(buf, pbDataField, data)
+
+	return DAG{
+		CID:      newCID(codecDAGPB, buf),
+		Size:     size + uint64(len(buf)),
+		fileSize: fileSize,
+	}
+}
+
+// appendVarintField appends a protobuf varint field.
+func appendVarintField(buf []byte, field int, v uint64) []byte {
+	buf = appendUvarint(buf, uint64(field<<3|0))
+	return appendUvarint(buf, v)
+}
+
+// appendBytesField appends a protobuf length-delimited field.
+func appendBytesField(buf []byte, field int, v []byte) []byte {
+	buf = appendUvarint(buf, uint64(field<<3|2))
+	buf = appendUvarint(buf, uint64(len(v)))
+	return append(buf, v...)
+}
+
+// appendUvarint appends the varint encoding of v to buf.
+func appendUvarint(buf []byte, v uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	return append(buf, b[:n]...)
+}
@@ -0,0 +1,216 @@
+package ipfs
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/h-fam/errdiff"
+)
+
+// yes returns the first n bytes of the output of `yes ethier`, a repeating
+// pattern that results in different contents for every chunk.
+func yes(n int) []byte {
+	return bytes.Repeat([]byte("ethier\n"), n/7+1)[:n]
+}
+
+func TestKnownCIDs(t *testing.T) {
+	emptyDir, err := Directory(nil)
+	if err != nil {
+		t.Fatalf("Directory(nil) error %v", err)
+	}
+
+	// Equivalent to:
+	//   hello.txt (hello world, without a trailing newline)
+	//   sub/ethier.txt (yes ethier | head -c 1000000)
+	sub, err := Directory(map[string]DAG{"ethier.txt": File(yes(1e6))})
+	if err != nil {
+		t.Fatalf("Directory({ethier.txt}) error %v", err)
+	}
+	dir, err := Directory(map[string]DAG{
+		"hello.txt": File([]byte("hello world")),
+		"sub":       sub,
+	})
+	if err != nil {
+		t.Fatalf("Directory({hello.txt, sub}) error %v", err)
+	}
+
+	tests := []struct {
+		name string
+		dag  DAG
+		want string
+	}{
+		{
+			name: "empty file",
+			dag:  File(nil),
+			want: "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku",
+		},
+		{
+			name: "hello world",
+			dag:  File([]byte("hello world")),
+			want: "bafkreifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n5e",
+		},
+		{
+			name: "empty directory",
+			dag:  emptyDir,
+			want: "bafybeiczsscdsbs7ffqz55asqdf3smv6klcw3gofszvwlyarci47bgf354",
+		},
+		{
+			name: "multiple chunks",
+			dag:  File(yes(1e6)),
+			want: "bafybeibjcjyuu2qoywftsfvd5bjsbee7agfv7oz4ypb7zdfakl5mcaze5q",
+		},
+		{
+			// Requires a second layer of links, the last branch of which has
+			// only one leaf.
+			name: "more than MaxLinks chunks",
+			dag:  File(yes(ChunkSize*MaxLinks + 1)),
+			want: "bafybeignzqmljzn4pbam65knbg6hbkda3kdkz77c4ltndojbwnxjntupai",
+		},
+		{
+			name: "nested directory",
+			dag:  dir,
+			want: "bafybeic4voasmenxqsp2n4hor2qcdyxas3m5rrlcfdt7c4ihqppookzo4y",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dag.CID.String(); got != tt.want {
+				t.Errorf("CID.String() got %q; want %q", got, tt.want)
+			}
+			if got, want := tt.dag.CID.URL(), "ipfs://"+tt.want; got != want {
+				t.Errorf("CID.URL() got %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestFileChunking(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		// wantCodec is the codec of the root node; raw for single chunks.
+		wantCodec uint64
+	}{
+		{
+			name:      "exactly one chunk",
+			size:      ChunkSize,
+			wantCodec: codecRaw,
+		},
+		{
+			name:      "two chunks",
+			size:      ChunkSize + 1,
+			wantCodec: codecDAGPB,
+		},
+		{
+			name:      "two layers",
+			size:      ChunkSize*MaxLinks + 1,
+			wantCodec: codecDAGPB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := bytes.Repeat([]byte{42}, tt.size)
+			got := File(data)
+
+			if got.CID.codec != tt.wantCodec {
+				t.Errorf("File([%d bytes]).CID codec = %#x; want %#x", tt.size, got.CID.codec, tt.wantCodec)
+			}
+			if got.fileSize != uint64(tt.size) {
+				t.Errorf("File([%d bytes]) file size = %d; want %d", tt.size, got.fileSize, tt.size)
+			}
+			if got.Size < uint64(tt.size) {
+				t.Errorf("File([%d bytes]).Size = %d; want >= file size", tt.size, got.Size)
+			}
+			if again := File(data); again != got {
+				t.Errorf("File([%d bytes]) not deterministic; got %+v then %+v", tt.size, got, again)
+			}
+
+			data[len(data)-1]++
+			if modified := File(data); modified.CID == got.CID {
+				t.Errorf("File([%d bytes]) CID unchanged after modifying last byte", tt.size)
+			}
+		})
+	}
+}
+
+func TestDirectory(t *testing.T) {
+	a := File([]byte("a"))
+	b := File([]byte("b"))
+
+	ab, err := Directory(map[string]DAG{"a": a, "b": b})
+	if err != nil {
+		t.Fatalf("Directory({a,b}) error %v", err)
+	}
+	ba, err := Directory(map[string]DAG{"a": b, "b": a})
+	if err != nil {
+		t.Fatalf("Directory({a:b,b:a}) error %v", err)
+	}
+	if ab.CID == ba.CID {
+		t.Errorf("Directory() CID independent of entry names")
+	}
+	if ab.Size != ba.Size {
+		t.Errorf("Directory() sizes differ for swapped entries of equal size; got %d and %d", ab.Size, ba.Size)
+	}
+
+	nested, err := Directory(map[string]DAG{"dir": ab})
+	if err != nil {
+		t.Fatalf("Directory({dir}) error %v", err)
+	}
+	if nested.Size <= ab.Size {
+		t.Errorf("Directory({dir}).Size = %d; want > child size %d", nested.Size, ab.Size)
+	}
+}
+
+// numberedEntries returns n directory entries named by decimal index, as for
+// an NFT collection's metadata.
+func numberedEntries(n int) map[string]DAG {
+	entries := make(map[string]DAG)
+	for i := 0; i < n; i++ {
+		entries[strconv.Itoa(i)] = File([]byte(strconv.Itoa(i)))
+	}
+	return entries
+}
+
+func TestDirectoryErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		entries        map[string]DAG
+		errDiffAgainst interface{}
+	}{
+		{
+			name:    "valid",
+			entries: map[string]DAG{"0": {}, "1.png": {}},
+		},
+		{
+			name:    "large but unsharded",
+			entries: numberedEntries(6000),
+		},
+		{
+			name:           "sharded",
+			entries:        numberedEntries(7000),
+			errDiffAgainst: "would be sharded",
+		},
+		{
+			name:           "empty name",
+			entries:        map[string]DAG{"": {}},
+			errDiffAgainst: "empty",
+		},
+		{
+			name:           "slash",
+			entries:        map[string]DAG{"a/b": {}},
+			errDiffAgainst: "slash",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Directory(tt.entries)
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Errorf("Directory(%v) %s", tt.entries, diff)
+			}
+		})
+	}
+}
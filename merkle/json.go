@@ -0,0 +1,95 @@
+package merkle
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// standardV1 is the JSON format used by OpenZeppelin's StandardMerkleTree.dump()
+// and StandardMerkleTree.load().
+type standardV1 struct {
+	Format       string          `json:"format"`
+	Tree         []common.Hash   `json:"tree"`
+	Values       []standardValue `json:"values"`
+	LeafEncoding []string        `json:"leafEncoding"`
+}
+
+type standardValue struct {
+	Value     [2]string `json:"value"`
+	TreeIndex int       `json:"treeIndex"`
+}
+
+const standardV1Format = "standard-v1"
+
+var leafEncoding = []string{"address", "uint256"}
+
+// MarshalJSON marshals the Tree in the standard-v1 format of OpenZeppelin's
+// @openzeppelin/merkle-tree library, allowing it to be loaded with
+// StandardMerkleTree.load().
+func (t *Tree) MarshalJSON() ([]byte, error) {
+	dump := standardV1{
+		Format:       standardV1Format,
+		Tree:         t.nodes,
+		LeafEncoding: leafEncoding,
+	}
+	for i, l := range t.leaves {
+		dump.Values = append(dump.Values, standardValue{
+			Value:     [2]string{l.Address.Hex(), l.Amount.String()},
+			TreeIndex: t.treeIndex[i],
+		})
+	}
+	return json.Marshal(dump)
+}
+
+// UnmarshalJSON parses a Tree from the standard-v1 format. The tree is rebuilt
+// from its values and an error is returned if it differs from the parsed tree.
+func (t *Tree) UnmarshalJSON(buf []byte) error {
+	var dump standardV1
+	if err := json.Unmarshal(buf, &dump); err != nil {
+		return err
+	}
+	if dump.Format != standardV1Format {
+		return fmt.Errorf("unsupported format %q", dump.Format)
+	}
+	if len(dump.LeafEncoding) != len(leafEncoding) || dump.LeafEncoding[0] != leafEncoding[0] || dump.LeafEncoding[1] != leafEncoding[1] {
+		return fmt.Errorf("unsupported leaf encoding %q; must be %q", dump.LeafEncoding, leafEncoding)
+	}
+
+	leaves := make([]Leaf, len(dump.Values))
+	for i, v := range dump.Values {
+		if !common.IsHexAddress(v.Value[0]) {
+			return fmt.Errorf("value %d: invalid address %q", i, v.Value[0])
+		}
+		amt, err := ParseAmount(v.Value[1])
+		if err != nil {
+			return fmt.Errorf("value %d: %v", i, err)
+		}
+		leaves[i] = Leaf{
+			Address: common.HexToAddress(v.Value[0]),
+			Amount:  amt,
+		}
+	}
+
+	rebuilt, err := New(leaves)
+	if err != nil {
+		return err
+	}
+	if len(rebuilt.nodes) != len(dump.Tree) {
+		return fmt.Errorf("tree has %d nodes; rebuilt from values has %d", len(dump.Tree), len(rebuilt.nodes))
+	}
+	for i, n := range dump.Tree {
+		if n != rebuilt.nodes[i] {
+			return fmt.Errorf("tree node %d = %v; rebuilt from values = %v", i, n, rebuilt.nodes[i])
+		}
+	}
+	for i, v := range dump.Values {
+		if got := rebuilt.treeIndex[i]; got != v.TreeIndex {
+			return fmt.Errorf("value %d has tree index %d; rebuilt from values = %d", i, v.TreeIndex, got)
+		}
+	}
+
+	*t = *rebuilt
+	return nil
+}
@@ -0,0 +1,193 @@
+// Package merkle builds Merkle-tree allow-lists that are compatible with
+// OpenZeppelin's MerkleProof.sol and with the standard-v1 trees produced by
+// their @openzeppelin/merkle-tree JavaScript library.
+//
+// Leaves are (address, amount) pairs, hashed as
+// keccak256(bytes.concat(keccak256(abi.encode(address, amount)))), which is
+// the pattern that should be reproduced on-chain when verifying proofs.
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// A Leaf is a single allow-list entry.
+type Leaf struct {
+	Address common.Address
+	Amount  *big.Int
+}
+
+// Hash returns keccak256(bytes.concat(keccak256(abi.encode(l.Address,
+// l.Amount)))). The double hashing of OpenZeppelin's standard tree protects
+// against second-preimage attacks as leaves can't be confused with internal
+// nodes.
+func (l Leaf) Hash() common.Hash {
+	var enc [64]byte
+	copy(enc[12:32], l.Address.Bytes())
+	if l.Amount != nil {
+		l.Amount.FillBytes(enc[32:])
+	}
+	return crypto.Keccak256Hash(crypto.Keccak256(enc[:]))
+}
+
+// ParseAmount parses s as a decimal amount, or as hexadecimal if it has a 0x
+// prefix. Unlike big.Int.SetString() with base 0, leading zeros don't imply
+// octal and underscores are rejected, so zero-padded input can't silently
+// change the Merkle root. Signs are also rejected.
+func ParseAmount(s string) (*big.Int, error) {
+	digits, base := s, 10
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		digits, base = s[2:], 16
+	}
+	if digits == "" || digits[0] == '-' || digits[0] == '+' {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	n, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	return n, nil
+}
+
+// validate returns an error if l.Amount is nil or doesn't fit in a uint256.
+func (l Leaf) validate() error {
+	if l.Amount == nil {
+		return fmt.Errorf("nil amount for %v", l.Address)
+	}
+	if l.Amount.Sign() == -1 || l.Amount.BitLen() > 256 {
+		return fmt.Errorf("amount %v for %v out of uint256 range", l.Amount, l.Address)
+	}
+	return nil
+}
+
+// A Tree is a Merkle tree of Leaves, laid out in the same manner as
+// OpenZeppelin's StandardMerkleTree such that roots and proofs are identical
+// for the same input.
+type Tree struct {
+	leaves []Leaf
+	// nodes is a complete binary tree in array form, with the root at index 0
+	// and the children of node i at 2i+1 and 2i+2.
+	nodes []common.Hash
+	// treeIndex[i] is the index in nodes of leaves[i].
+	treeIndex []int
+	// byAddress maps from an address to its index in leaves.
+	byAddress map[common.Address]int
+}
+
+// New builds a Tree from the leaves. Leaf order is irrelevant to the resulting
+// root as leaves are sorted by hash, but it is preserved by Tree.Leaves().
+// Addresses must be unique.
+func New(leaves []Leaf) (*Tree, error) {
+	if len(leaves) == 0 {
+		return nil, errors.New("no leaves")
+	}
+
+	t := &Tree{
+		leaves:    make([]Leaf, len(leaves)),
+		treeIndex: make([]int, len(leaves)),
+		byAddress: make(map[common.Address]int),
+	}
+	copy(t.leaves, leaves)
+
+	type hashed struct {
+		hash       common.Hash
+		valueIndex int
+	}
+	hashes := make([]hashed, len(leaves))
+	for i, l := range leaves {
+		if err := l.validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := t.byAddress[l.Address]; ok {
+			return nil, fmt.Errorf("duplicate address %v", l.Address)
+		}
+		t.byAddress[l.Address] = i
+		hashes[i] = hashed{l.Hash(), i}
+	}
+	sort.SliceStable(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i].hash[:], hashes[j].hash[:]) == -1
+	})
+
+	n := len(hashes)
+	t.nodes = make([]common.Hash, 2*n-1)
+	for i, h := range hashes {
+		idx := len(t.nodes) - 1 - i
+		t.nodes[idx] = h.hash
+		t.treeIndex[h.valueIndex] = idx
+	}
+	for i := len(t.nodes) - 1 - n; i >= 0; i-- {
+		t.nodes[i] = hashPair(t.nodes[2*i+1], t.nodes[2*i+2])
+	}
+	return t, nil
+}
+
+// hashPair returns the commutative hash of a and b, as used by MerkleProof.sol.
+func hashPair(a, b common.Hash) common.Hash {
+	if bytes.Compare(a[:], b[:]) == 1 {
+		a, b = b, a
+	}
+	return crypto.Keccak256Hash(a[:], b[:])
+}
+
+// Root returns the Merkle root of the Tree.
+func (t *Tree) Root() common.Hash {
+	return t.nodes[0]
+}
+
+// Leaves returns the Tree's leaves in the order in which they were provided.
+func (t *Tree) Leaves() []Leaf {
+	l := make([]Leaf, len(t.leaves))
+	copy(l, t.leaves)
+	return l
+}
+
+// Proof returns the Merkle proof of the i'th leaf, as originally passed to
+// New(). The returned value can be passed directly to abigen bindings as a
+// bytes32[].
+func (t *Tree) Proof(i int) ([][32]byte, error) {
+	if i < 0 || i >= len(t.leaves) {
+		return nil, fmt.Errorf("leaf index %d out of range [0,%d)", i, len(t.leaves))
+	}
+
+	proof := [][32]byte{}
+	for idx := t.treeIndex[i]; idx > 0; idx = (idx - 1) / 2 {
+		sibling := idx + 1
+		if idx%2 == 0 {
+			sibling = idx - 1
+		}
+		proof = append(proof, t.nodes[sibling])
+	}
+	return proof, nil
+}
+
+// ProofFor returns the Leaf carrying the address, along with its Merkle proof.
+func (t *Tree) ProofFor(addr common.Address) (Leaf, [][32]byte, error) {
+	i, ok := t.byAddress[addr]
+	if !ok {
+		return Leaf{}, nil, fmt.Errorf("address %v not in tree", addr)
+	}
+	proof, err := t.Proof(i)
+	if err != nil {
+		return Leaf{}, nil, err
+	}
+	return t.leaves[i], proof, nil
+}
+
+// Verify reports whether the proof demonstrates that the leaf is included in a
+// tree with the root. It is equivalent to MerkleProof.verify(proof, root,
+// leaf.Hash()).
+func Verify(root common.Hash, leaf Leaf, proof [][32]byte) bool {
+	h := leaf.Hash()
+	for _, p := range proof {
+		h = hashPair(h, p)
+	}
+	return h == root
+}
@@ -0,0 +1,205 @@
+package merkle
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+)
+
+// leaves returns n Leaves with deterministic addresses and amounts.
+func leaves(n int) []Leaf {
+	var l []Leaf
+	for i := 0; i < n; i++ {
+		l = append(l, Leaf{
+			Address: common.BigToAddress(big.NewInt(int64(i + 1))),
+			Amount:  big.NewInt(int64(i % 3)),
+		})
+	}
+	return l
+}
+
+func TestOpenZeppelinCompatibility(t *testing.T) {
+	// Example from the @openzeppelin/merkle-tree README.
+	tree, err := New([]Leaf{
+		{
+			Address: common.HexToAddress("0x1111111111111111111111111111111111111111"),
+			Amount:  new(big.Int).Mul(big.NewInt(5), big.NewInt(1e18)),
+		},
+		{
+			Address: common.HexToAddress("0x2222222222222222222222222222222222222222"),
+			Amount:  new(big.Int).Mul(big.NewInt(25), big.NewInt(1e17)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error %v", err)
+	}
+
+	if got, want := tree.Root(), common.HexToHash("0xd4dee0beab2d53f2cc83e567171bd2820e49898130a22622b10ead383e90bd77"); got != want {
+		t.Errorf("%T.Root() got %v; want %v", tree, got, want)
+	}
+}
+
+func TestProofs(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		t.Run(fmt.Sprintf("%d leaves", n), func(t *testing.T) {
+			ls := leaves(n)
+			tree, err := New(ls)
+			if err != nil {
+				t.Fatalf("New() error %v", err)
+			}
+
+			for i, l := range ls {
+				proof, err := tree.Proof(i)
+				if err != nil {
+					t.Fatalf("%T.Proof(%d) error %v", tree, i, err)
+				}
+				if !Verify(tree.Root(), l, proof) {
+					t.Errorf("Verify(%T.Root(), [leaf %d], %T.Proof(%d)) got false; want true", tree, i, tree, i)
+				}
+
+				tampered := Leaf{Address: l.Address, Amount: new(big.Int).Add(l.Amount, big.NewInt(1))}
+				if Verify(tree.Root(), tampered, proof) {
+					t.Errorf("Verify(%T.Root(), [leaf %d with modified amount], %T.Proof(%d)) got true; want false", tree, i, tree, i)
+				}
+
+				gotLeaf, gotProof, err := tree.ProofFor(l.Address)
+				if err != nil {
+					t.Fatalf("%T.ProofFor(%v) error %v", tree, l.Address, err)
+				}
+				if diff := cmp.Diff(l.Hash(), gotLeaf.Hash()); diff != "" {
+					t.Errorf("%T.ProofFor(%v) leaf diff (-want +got):\n%s", tree, l.Address, diff)
+				}
+				if diff := cmp.Diff(proof, gotProof); diff != "" {
+					t.Errorf("%T.ProofFor(%v) proof diff (-want +got):\n%s", tree, l.Address, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderIndependence(t *testing.T) {
+	ls := leaves(10)
+	a, err := New(ls)
+	if err != nil {
+		t.Fatalf("New() error %v", err)
+	}
+
+	for i, j := 0, len(ls)-1; i < j; i, j = i+1, j-1 {
+		ls[i], ls[j] = ls[j], ls[i]
+	}
+	b, err := New(ls)
+	if err != nil {
+		t.Fatalf("New([reversed]) error %v", err)
+	}
+
+	if a.Root() != b.Root() {
+		t.Errorf("New() with reversed leaves got different roots %v and %v", a.Root(), b.Root())
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		leaves         []Leaf
+		errDiffAgainst interface{}
+	}{
+		{
+			name:           "empty",
+			leaves:         nil,
+			errDiffAgainst: "no leaves",
+		},
+		{
+			name:           "duplicate address",
+			leaves:         append(leaves(3), leaves(1)...),
+			errDiffAgainst: "duplicate address",
+		},
+		{
+			name:           "nil amount",
+			leaves:         []Leaf{{Address: common.Address{1}}},
+			errDiffAgainst: "nil amount",
+		},
+		{
+			name:           "negative amount",
+			leaves:         []Leaf{{Address: common.Address{1}, Amount: big.NewInt(-1)}},
+			errDiffAgainst: "out of uint256 range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.leaves)
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Errorf("New() %s", diff)
+			}
+		})
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	tree, err := New(leaves(7))
+	if err != nil {
+		t.Fatalf("New() error %v", err)
+	}
+
+	buf, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatalf("json.Marshal(%T) error %v", tree, err)
+	}
+	if !strings.Contains(string(buf), `"format":"standard-v1"`) {
+		t.Errorf("json.Marshal(%T) got %s; want standard-v1 format", tree, buf)
+	}
+
+	got := new(Tree)
+	if err := json.Unmarshal(buf, got); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error %v", buf, err)
+	}
+	if got.Root() != tree.Root() {
+		t.Errorf("json.Unmarshal(json.Marshal(%T)).Root() got %v; want %v", tree, got.Root(), tree.Root())
+	}
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := strings.Replace(string(buf), tree.Root().Hex(), common.Hash{}.Hex(), 1)
+		err := json.Unmarshal([]byte(tampered), new(Tree))
+		if diff := errdiff.Substring(err, "rebuilt from values"); diff != "" {
+			t.Errorf("json.Unmarshal([tampered root]) %s", diff)
+		}
+	})
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in             string
+		want           *big.Int
+		errDiffAgainst interface{}
+	}{
+		{in: "10", want: big.NewInt(10)},
+		{in: "010", want: big.NewInt(10)},
+		{in: "0x10", want: big.NewInt(16)},
+		{in: "0X0f", want: big.NewInt(15)},
+		{in: "1_000", errDiffAgainst: "invalid amount"},
+		{in: "-1", errDiffAgainst: "invalid amount"},
+		{in: "+1", errDiffAgainst: "invalid amount"},
+		{in: "0x", errDiffAgainst: "invalid amount"},
+		{in: "", errDiffAgainst: "invalid amount"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.in)
+		if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+			t.Errorf("ParseAmount(%q) %s", tt.in, diff)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got.Cmp(tt.want) != 0 {
+			t.Errorf("ParseAmount(%q) got %v; want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -261,3 +261,38 @@ func TestSignerManagement(t *testing.T) {
 		t.Errorf("%T.NeedsSenderSignature([signer removed]) %s", checker, diff)
 	}
 }
+
+func TestVouchers(t *testing.T) {
+	sim, checker := deploy(t)
+	signer := goodSigners[0]
+
+	t.Run("single use", func(t *testing.T) {
+		v, err := signer.SignVoucherWithNonce([]byte("hello"))
+		if err != nil {
+			t.Fatalf("%T.SignVoucherWithNonce() error %v", signer, err)
+		}
+		if _, err := checker.NeedsSignature(sim.Acc(0), v.Data, v.Nonce, v.Signature); err != nil {
+			t.Errorf("NeedsSignature(%T) error %v", v, err)
+		}
+	})
+
+	t.Run("reusable", func(t *testing.T) {
+		v, err := signer.SignVoucher([]byte("hello"))
+		if err != nil {
+			t.Fatalf("%T.SignVoucher() error %v", signer, err)
+		}
+		if _, err := checker.NeedsReusableSignature(nil, v.Data, v.Signature); err != nil {
+			t.Errorf("NeedsReusableSignature(%T) error %v", v, err)
+		}
+	})
+
+	t.Run("sender", func(t *testing.T) {
+		v, err := signer.SignAddressVoucher(sim.Addr(arbitrary))
+		if err != nil {
+			t.Fatalf("%T.SignAddressVoucher() error %v", signer, err)
+		}
+		if _, err := checker.NeedsSenderSignature(sim.CallFrom(arbitrary), v.Signature); err != nil {
+			t.Errorf("NeedsSenderSignature(%T) error %v", v, err)
+		}
+	})
+}